@@ -0,0 +1,127 @@
+package batch
+
+import (
+	"context"
+	"encoding/csv"
+	"heat-transfer/scenario"
+	"io"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// Row is the outcome of one batch run. Err is set instead of the results
+// when the run fails, so one bad combination does not abort the batch.
+type Row struct {
+	Index          int     `json:"index"`
+	Point          Point   `json:"point"`
+	Coeff          float64 `json:"coeff"`
+	PeakInsideTemp float64 `json:"peak_inside_temp"`
+	MinInsideTemp  float64 `json:"min_inside_temp"`
+	ACMinutes      int     `json:"ac_minutes"`
+	DailyKWh       float64 `json:"daily_kwh"`
+	MonthlyACCost  float64 `json:"monthly_ac_cost"`
+	MaterialMin    float64 `json:"material_min"`
+	MaterialCost   float64 `json:"material_cost"`
+	MaterialMax    float64 `json:"material_max"`
+	Err            string  `json:"error,omitempty"`
+}
+
+// Run expands the grid and simulates every point on a pool of workers.
+// workers <= 0 uses one worker per CPU. Rows are returned in grid order.
+// progress, when not nil, is called after every finished point.
+func Run(ctx context.Context, base scenario.Scenario, grid Grid, outsideTemps [840]float64, workers int, progress func(done, total int)) ([]Row, error) {
+	scenarios, points, err := grid.Expand(base)
+	if err != nil {
+		return nil, err
+	}
+
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	rows := make([]Row, len(scenarios))
+	jobs := make(chan int)
+	var done atomic.Int64
+
+	var wg sync.WaitGroup
+	for range min(workers, len(scenarios)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				rows[i] = runOne(ctx, i, points[i], scenarios[i], outsideTemps)
+				if n := done.Add(1); progress != nil {
+					progress(int(n), len(scenarios))
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := range scenarios {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func runOne(ctx context.Context, i int, p Point, s scenario.Scenario, outsideTemps [840]float64) Row {
+	row := Row{Index: i, Point: p}
+
+	r, err := scenario.RunContext(ctx, s, outsideTemps, nil)
+	if err != nil {
+		row.Err = err.Error()
+		return row
+	}
+
+	row.Coeff = r.Coeff
+	row.PeakInsideTemp = r.PeakInsideTemp
+	row.MinInsideTemp = r.MinInsideTemp
+	row.ACMinutes = r.ACMinutes
+	row.DailyKWh = r.DailyKWh
+	row.MonthlyACCost = r.MonthlyACCost
+	row.MaterialMin = r.MaterialCost.Min
+	row.MaterialCost = r.MaterialCost.Typical
+	row.MaterialMax = r.MaterialCost.Max
+	return row
+}
+
+// WriteCSV writes the results table, costs in THB.
+func WriteCSV(w io.Writer, rows []Row) error {
+	cw := csv.NewWriter(w)
+	f := func(v float64, prec int) string { return strconv.FormatFloat(v, 'f', prec, 64) }
+
+	header := []string{
+		"index", "material", "thickness_m", "set_temp_c", "ac_power_w", "schedule",
+		"u_value", "peak_inside_c", "min_inside_c", "ac_minutes", "daily_kwh", "monthly_ac_cost",
+		"material_cost_min", "material_cost", "material_cost_max", "error",
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, r := range rows {
+		rec := []string{
+			strconv.Itoa(r.Index), r.Point.Material, f(r.Point.Thickness, 3), f(r.Point.SetTemp, 1), f(r.Point.ACPower, 0), r.Point.Schedule.String(),
+			f(r.Coeff, 4), f(r.PeakInsideTemp, 2), f(r.MinInsideTemp, 2), strconv.Itoa(r.ACMinutes), f(r.DailyKWh, 3), f(r.MonthlyACCost, 2),
+			f(r.MaterialMin, 2), f(r.MaterialCost, 2), f(r.MaterialMax, 2), r.Err,
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,233 @@
+package batch
+
+import (
+	"errors"
+	"fmt"
+	"heat-transfer/calc"
+	"heat-transfer/scenario"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Schedule is an AC operating window in minutes after 05:00.
+type Schedule struct {
+	Name    string `json:"name"`
+	OnTime  int    `json:"on_time"`
+	OffTime int    `json:"off_time"`
+}
+
+// Grid lists the values to sweep. Empty dimensions keep the base scenario's
+// value. Thickness and material replace the outermost layer of the base.
+type Grid struct {
+	Thicknesses []float64  `json:"thicknesses"` // m
+	Materials   []string   `json:"materials"`
+	SetTemps    []float64  `json:"set_temps"` // °C
+	ACPowers    []float64  `json:"ac_powers"` // W of cooling
+	Schedules   []Schedule `json:"schedules"`
+}
+
+// Point is one combination of grid values.
+type Point struct {
+	Thickness float64  `json:"thickness"`
+	Material  string   `json:"material"`
+	SetTemp   float64  `json:"set_temp"`
+	ACPower   float64  `json:"ac_power"`
+	Schedule  Schedule `json:"schedule"`
+}
+
+func (g Grid) Size() int {
+	n := 1
+	for _, l := range []int{len(g.Thicknesses), len(g.Materials), len(g.SetTemps), len(g.ACPowers), len(g.Schedules)} {
+		n *= max(l, 1)
+	}
+	return n
+}
+
+// Expand returns one scenario per grid point, derived from base.
+func (g Grid) Expand(base scenario.Scenario) ([]scenario.Scenario, []Point, error) {
+	if len(base.Layers) == 0 && (len(g.Thicknesses) > 0) != (len(g.Materials) > 0) {
+		return nil, nil, errors.New("base scenario has no layers, so both thicknesses and materials are required")
+	}
+
+	ac := calc.ACParams{}
+	if base.AC != nil {
+		ac = *base.AC
+	}
+	defaultLayer := calc.Layer{}
+	if len(base.Layers) > 0 {
+		defaultLayer = base.Layers[len(base.Layers)-1]
+	}
+
+	thicknesses := orDefault(g.Thicknesses, defaultLayer.Thickness)
+	materials := orDefault(g.Materials, defaultLayer.Material)
+	setTemps := orDefault(g.SetTemps, ac.SetTemp)
+	powers := orDefault(g.ACPowers, math.Abs(ac.CoolingPower))
+	schedules := orDefault(g.Schedules, Schedule{OnTime: ac.OnTime, OffTime: ac.OffTime})
+	sweepsLayer := len(g.Thicknesses) > 0 || len(g.Materials) > 0
+	sweepsAC := len(g.SetTemps) > 0 || len(g.ACPowers) > 0 || len(g.Schedules) > 0
+
+	var list []scenario.Scenario
+	var points []Point
+	for _, t := range thicknesses {
+		for _, m := range materials {
+			for _, st := range setTemps {
+				for _, pw := range powers {
+					for _, sch := range schedules {
+						s := base
+						if sweepsLayer {
+							// the swept layers define the U value
+							s.Coeff = 0
+							s.Layers = append([]calc.Layer(nil), base.Layers...)
+							layer := calc.Layer{Material: m, Thickness: t}
+							if len(s.Layers) == 0 {
+								s.Layers = []calc.Layer{layer}
+							} else {
+								s.Layers[len(s.Layers)-1] = layer
+							}
+						}
+
+						if base.AC != nil || sweepsAC {
+							s.AC = &calc.ACParams{
+								Enabled:      base.AC == nil || base.AC.Enabled,
+								OnTime:       sch.OnTime,
+								OffTime:      sch.OffTime,
+								SetTemp:      st,
+								CoolingPower: -math.Abs(pw),
+							}
+						}
+
+						p := Point{Thickness: t, Material: m, SetTemp: st, ACPower: pw, Schedule: sch}
+						s.Name = p.String()
+						list = append(list, s)
+						points = append(points, p)
+					}
+				}
+			}
+		}
+	}
+
+	return list, points, nil
+}
+
+func orDefault[T any](values []T, def T) []T {
+	if len(values) == 0 {
+		return []T{def}
+	}
+	return values
+}
+
+func (p Point) String() string {
+	return fmt.Sprintf("%s %.3fm %.1f°C %.0fW %s", p.Material, p.Thickness, p.SetTemp, p.ACPower, p.Schedule)
+}
+
+func (s Schedule) String() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return fmt.Sprintf("%s-%s", clock(s.OnTime), clock(s.OffTime))
+}
+
+func clock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60+5, minutes%60)
+}
+
+// ParseRange parses "from:to:step" or a comma separated list of numbers.
+func ParseRange(s string) ([]float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	if parts := strings.Split(s, ":"); len(parts) == 3 {
+		var v [3]float64
+		for i, p := range parts {
+			f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid range %q: %w", s, err)
+			}
+			v[i] = f
+		}
+		from, to, step := v[0], v[1], v[2]
+		if step <= 0 || to < from {
+			return nil, fmt.Errorf("invalid range %q: step must be positive and to >= from", s)
+		}
+
+		var values []float64
+		// small tolerance so the end point survives rounding
+		for i := 0; from+float64(i)*step <= to+step*1e-9; i++ {
+			values = append(values, from+float64(i)*step)
+		}
+		return values, nil
+	}
+
+	var values []float64
+	for _, p := range strings.Split(s, ",") {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q: %w", p, err)
+		}
+		values = append(values, f)
+	}
+	return values, nil
+}
+
+// ParseMaterials splits a comma separated list of material keys, ignoring
+// spaces around them and empty entries.
+func ParseMaterials(s string) []string {
+	var keys []string
+	for _, k := range strings.Split(s, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// ParseSchedules parses a comma separated list of "HH:MM-HH:MM" windows.
+func ParseSchedules(s string) ([]Schedule, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	var list []Schedule
+	for _, item := range strings.Split(s, ",") {
+		on, off, ok := strings.Cut(strings.TrimSpace(item), "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid schedule %q, expected HH:MM-HH:MM", item)
+		}
+		onTime, err := ParseClock(on)
+		if err != nil {
+			return nil, err
+		}
+		offTime, err := ParseClock(off)
+		if err != nil {
+			return nil, err
+		}
+		if offTime < onTime {
+			return nil, fmt.Errorf("invalid schedule %q, off time is before on time", item)
+		}
+		list = append(list, Schedule{OnTime: onTime, OffTime: offTime})
+	}
+	return list, nil
+}
+
+// ParseClock converts "HH:MM" between 05:00 and 19:00 into minutes after 05:00.
+func ParseClock(s string) (int, error) {
+	hourStr, minStr, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	hour, hourErr := strconv.Atoi(hourStr)
+	minute, minErr := strconv.Atoi(minStr)
+	if hourErr != nil || minErr != nil || minute < 0 || minute >= 60 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+
+	total := (hour-5)*60 + minute
+	if total < 0 || total > 840 {
+		return 0, fmt.Errorf("time %q is outside the simulated 05:00 - 19:00 window", s)
+	}
+	return total, nil
+}
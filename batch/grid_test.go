@@ -0,0 +1,73 @@
+package batch
+
+import (
+	"context"
+	"heat-transfer/calc"
+	"heat-transfer/scenario"
+	"heat-transfer/scenario/scenariotest"
+	"testing"
+)
+
+func TestExpandCoeffOnly(t *testing.T) {
+	base, outside := scenariotest.Room()
+	grid := Grid{SetTemps: []float64{24, 26}}
+
+	list, points, err := grid.Expand(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || len(points) != 2 {
+		t.Fatalf("%d scenarios, want 2", len(list))
+	}
+	for i, s := range list {
+		if s.Layers != nil || s.Coeff != 1.5 {
+			t.Errorf("scenario %d: layers %v and U %g, want the base's U value kept", i, s.Layers, s.Coeff)
+		}
+		if s.AC.SetTemp != grid.SetTemps[i] {
+			t.Errorf("scenario %d: set temp %g, want %g", i, s.AC.SetTemp, grid.SetTemps[i])
+		}
+	}
+
+	rows, err := Run(context.Background(), base, grid, outside, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range rows {
+		if r.Err != "" {
+			t.Errorf("row %d failed: %s", r.Index, r.Err)
+		}
+	}
+	if rows[0].Coeff != 1.5 || rows[0].MinInsideTemp >= rows[1].MinInsideTemp {
+		t.Errorf("rows %+v and %+v, want the base U value and a cooler room at 24°C", rows[0], rows[1])
+	}
+}
+
+func TestExpandLayers(t *testing.T) {
+	base := scenario.Scenario{Name: "room", Width: 4, Height: 3, Depth: 5, Coeff: 1.5}
+
+	if _, _, err := (Grid{Thicknesses: []float64{0.1}}).Expand(base); err == nil {
+		t.Error("a thickness without a material should be refused when the base has no layers")
+	}
+
+	list, _, err := Grid{Thicknesses: []float64{0.1, 0.2}, Materials: []string{"brick"}}.Expand(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, s := range list {
+		if s.Coeff != 0 || len(s.Layers) != 1 || s.Layers[0].Material != "brick" {
+			t.Errorf("scenario %d: U %g with layers %v, want the swept layer alone", i, s.Coeff, s.Layers)
+		}
+	}
+
+	base.Layers = []calc.Layer{{Material: "plaster", Thickness: 0.01}, {Material: "brick", Thickness: 0.1}}
+	list, _, err = Grid{Thicknesses: []float64{0.3}}.Expand(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := list[0].Layers; len(got) != 2 || got[0].Thickness != 0.01 || got[1] != (calc.Layer{Material: "brick", Thickness: 0.3}) {
+		t.Errorf("layers %v, want the outer layer thickened", got)
+	}
+	if base.Layers[1].Thickness != 0.1 {
+		t.Error("expanding changed the base scenario's layers")
+	}
+}
@@ -1,20 +1,29 @@
 package calc
 
 import (
+	"context"
 	"errors"
 	"heat-transfer/constants"
 )
 
 // ac params struct
 type ACParams struct {
-	Enabled     bool
-	OnTime      int
-	OffTime     int
-	SetTemp     float64
-	CoolingPower float64
+	Enabled      bool    `json:"enabled"`
+	OnTime       int     `json:"on_time"`       // minutes after 05:00
+	OffTime      int     `json:"off_time"`      // minutes after 05:00
+	SetTemp      float64 `json:"set_temp"`      // °C
+	CoolingPower float64 `json:"cooling_power"` // W, negative when cooling
 }
 
 func CalculateTemperatureProfile(width, height, depth, insideTemp float64, outsideTemps [840]float64, heatTransferCoeff float64, acParams *ACParams) ([]float64, []float64, []bool) {
+	timeMinutes, insideProfile, acRunningProfile, _ := CalculateTemperatureProfileContext(context.Background(), width, height, depth, insideTemp, outsideTemps, heatTransferCoeff, acParams, nil)
+	return timeMinutes, insideProfile, acRunningProfile
+}
+
+// CalculateTemperatureProfileContext is CalculateTemperatureProfile with
+// cancellation. The context is checked once per simulated minute and
+// progress, when not nil, is called with the minutes done so far.
+func CalculateTemperatureProfileContext(ctx context.Context, width, height, depth, insideTemp float64, outsideTemps [840]float64, heatTransferCoeff float64, acParams *ACParams, progress func(done, total int)) ([]float64, []float64, []bool, error) {
 
 	volume := width * height * depth
 	totalArea := 2 * height * (width + depth)
@@ -82,6 +91,13 @@ func CalculateTemperatureProfile(width, height, depth, insideTemp float64, outsi
 		t := float64(i) * dt
 
 		if t >= nextRecordTime {
+			if err := ctx.Err(); err != nil {
+				return nil, nil, nil, err
+			}
+			if progress != nil {
+				progress(len(insideProfile), totalMinutes)
+			}
+
 			timeMinutes = append(timeMinutes, nextRecordTime/60.0)
 			insideProfile = append(insideProfile, Tcurrent)
 			acRunningProfile = append(acRunningProfile, acCompressorOn)
@@ -95,21 +111,26 @@ func CalculateTemperatureProfile(width, height, depth, insideTemp float64, outsi
 		Tcurrent = Tcurrent + (dt/6)*(k1+2*k2+2*k3+k4)
 	}
 
-	return timeMinutes, insideProfile, acRunningProfile
+	if progress != nil {
+		progress(totalMinutes, totalMinutes)
+	}
+
+	return timeMinutes, insideProfile, acRunningProfile, nil
 }
 
-func CalculateMaterialCost(x, y, z, t, costPerM3 float64) float64 {
+func CalculateMaterialCost(x, y, z, t float64, costPerM3 constants.PriceRange) constants.PriceRange {
 	s1 := x * y * t
 	s2 := y * z * t
 
-	return 2*(costPerM3*s1) + 2*(costPerM3*s2)
+	return costPerM3.Scale(2*s1 + 2*s2)
 }
 
 func CalculateCoeffByThickness(material string, thickness float64) (float64, error) {
-	k, exists := constants.ThermalConductivity[material]
-	if !exists {
-		return 0, errors.New("material not found")
+	m, err := constants.GetMaterial(material)
+	if err != nil {
+		return 0, err
 	}
+	k := m.Conductivity
 
 	if thickness <= 0 {
 		return 0, errors.New("thickness must be greater than zero")
@@ -122,4 +143,39 @@ func CalculateCoeffByThickness(material string, thickness float64) (float64, err
 	uValue := 1 / rValue
 
 	return uValue, nil
-}
\ No newline at end of file
+}
+
+// CalculateCoeffByLayers returns the U value of layers in series.
+func CalculateCoeffByLayers(layers []Layer) (float64, error) {
+	if len(layers) == 0 {
+		return 0, errors.New("at least one layer is required")
+	}
+
+	rValue := 0.0
+	for _, l := range layers {
+		u, err := CalculateCoeffByThickness(l.Material, l.Thickness)
+		if err != nil {
+			return 0, err
+		}
+		rValue += 1 / u
+	}
+
+	return 1 / rValue, nil
+}
+
+// InfiltrationCoeff converts an air change rate into the equivalent extra U
+// value over the wall area used by CalculateTemperatureProfile.
+func InfiltrationCoeff(width, height, depth, insideTemp, airChangesPerHour float64) float64 {
+	wallArea := 2 * height * (width + depth)
+	if wallArea <= 0 || airChangesPerHour <= 0 {
+		return 0
+	}
+
+	rho := 101325 / (287 * (insideTemp + 273.15))
+	volume := width * height * depth
+
+	// W/K
+	ua := rho * 1005.0 * volume * airChangesPerHour / 3600.0
+
+	return ua / wallArea
+}
@@ -24,14 +24,28 @@ func GetResidentialRate() ElectricityRate {
 	}
 }
 
-func CalculateACElectricityCost(
+// ACCostBreakdown itemises the AC share of a monthly electricity bill in THB.
+type ACCostBreakdown struct {
+	DaysInMonth     int     `json:"days_in_month"`
+	ACMinutes       int     `json:"ac_minutes"` // per day
+	DailyKWh        float64 `json:"daily_kwh"`
+	MonthlyKWh      float64 `json:"monthly_kwh"`       // AC only
+	TotalMonthlyKWh float64 `json:"total_monthly_kwh"` // AC plus existing usage
+	EnergyCharge    float64 `json:"energy_charge"`
+	FtCharge        float64 `json:"ft_charge"`
+	ServiceCharge   float64 `json:"service_charge"`
+	VAT             float64 `json:"vat"`
+	Total           float64 `json:"total"`
+}
+
+func CalculateACCostBreakdown(
 	acParams *ACParams,
 	daysInMonth int,
 	existingUsage float64,
 	acRunningProfile []bool,
-) (float64, []bool) {
+) ACCostBreakdown {
 	if acParams == nil || !acParams.Enabled || len(acRunningProfile) == 0 {
-		return 0.0, nil
+		return ACCostBreakdown{}
 	}
 
 	if daysInMonth <= 0 {
@@ -74,7 +88,11 @@ func CalculateACElectricityCost(
 		remainingKWh -= blockKWh
 	}
 
-	acProportion := monthlyKWh / totalMonthlyKWh
+	// no usage at all, nothing to attribute to the AC
+	acProportion := 0.0
+	if totalMonthlyKWh > 0 {
+		acProportion = monthlyKWh / totalMonthlyKWh
+	}
 	acEnergyCharge := energyCharge * acProportion
 	ftCharge := monthlyKWh * rate.FtRate
 	serviceCharge := rate.ServiceFee * acProportion
@@ -82,9 +100,34 @@ func CalculateACElectricityCost(
 	// tax
 	subtotal := acEnergyCharge + ftCharge + serviceCharge
 	vat := subtotal * (rate.VatPercent / 100.0)
-	totalCost := subtotal + vat
 
-	return totalCost, acRunningProfile
+	return ACCostBreakdown{
+		DaysInMonth:     daysInMonth,
+		ACMinutes:       totalACMinutes,
+		DailyKWh:        dailyKWh,
+		MonthlyKWh:      monthlyKWh,
+		TotalMonthlyKWh: totalMonthlyKWh,
+		EnergyCharge:    acEnergyCharge,
+		FtCharge:        ftCharge,
+		ServiceCharge:   serviceCharge,
+		VAT:             vat,
+		Total:           subtotal + vat,
+	}
+}
+
+func CalculateACElectricityCost(
+	acParams *ACParams,
+	daysInMonth int,
+	existingUsage float64,
+	acRunningProfile []bool,
+) (float64, []bool) {
+	if acParams == nil || !acParams.Enabled || len(acRunningProfile) == 0 {
+		return 0.0, nil
+	}
+
+	breakdown := CalculateACCostBreakdown(acParams, daysInMonth, existingUsage, acRunningProfile)
+
+	return breakdown.Total, acRunningProfile
 }
 
 func EstimateACOperatingCost(acParams *ACParams, acRunningProfile []bool, existingUsage float64) (float64, float64, float64, []bool) {
@@ -0,0 +1,235 @@
+package calc
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"heat-transfer/constants"
+	"io"
+	"strconv"
+)
+
+const (
+	ElementWall  = "wall"
+	ElementRoof  = "roof"
+	ElementFloor = "floor"
+)
+
+// Layer is one material layer of an envelope element, thickness in m.
+type Layer struct {
+	Material  string  `json:"material"`
+	Thickness float64 `json:"thickness"`
+}
+
+// Opening is a window or door cut out of an envelope element, dimensions in m.
+type Opening struct {
+	Name   string  `json:"name"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+	Count  int     `json:"count"`
+}
+
+func (o Opening) Area() float64 {
+	count := o.Count
+	if count <= 0 {
+		count = 1
+	}
+	return o.Width * o.Height * float64(count)
+}
+
+// EnvelopeElement is a wall, roof or floor with its gross dimensions in m.
+type EnvelopeElement struct {
+	Name     string    `json:"name"`
+	Kind     string    `json:"kind"`
+	Width    float64   `json:"width"`
+	Height   float64   `json:"height"`
+	Corners  int       `json:"corners"` // external corners the layers wrap around
+	Openings []Opening `json:"openings,omitempty"`
+	Layers   []Layer   `json:"layers"`
+}
+
+func (e EnvelopeElement) GrossArea() float64 {
+	return e.Width * e.Height
+}
+
+func (e EnvelopeElement) NetArea() float64 {
+	area := e.GrossArea()
+	for _, o := range e.Openings {
+		area -= o.Area()
+	}
+	return area
+}
+
+// RoomEnvelope builds the four walls, and optionally the roof and floor, of a
+// width x height x depth room with the same layers on every element.
+func RoomEnvelope(width, height, depth float64, layers []Layer, roof, floor bool) []EnvelopeElement {
+	elements := []EnvelopeElement{
+		{Name: "Wall 1", Kind: ElementWall, Width: width, Height: height, Corners: 2, Layers: layers},
+		{Name: "Wall 2", Kind: ElementWall, Width: depth, Height: height, Corners: 2, Layers: layers},
+		{Name: "Wall 3", Kind: ElementWall, Width: width, Height: height, Corners: 2, Layers: layers},
+		{Name: "Wall 4", Kind: ElementWall, Width: depth, Height: height, Corners: 2, Layers: layers},
+	}
+	if roof {
+		elements = append(elements, EnvelopeElement{Name: "Roof", Kind: ElementRoof, Width: width, Height: depth, Layers: layers})
+	}
+	if floor {
+		elements = append(elements, EnvelopeElement{Name: "Floor", Kind: ElementFloor, Width: width, Height: depth, Layers: layers})
+	}
+	return elements
+}
+
+type TakeoffParams struct {
+	WasteFactor float64                         // extra material ordered, 0.1 = 10%
+	LabourPerM2 constants.PriceRange            // installation cost per m² per layer
+	Labour      map[string]constants.PriceRange // per-material override of LabourPerM2
+}
+
+func DefaultTakeoffParams() TakeoffParams {
+	return TakeoffParams{
+		WasteFactor: 0.10,
+		LabourPerM2: constants.PriceRange{Min: 150, Typical: 250, Max: 350, Currency: constants.BaseCurrency, Region: "TH"},
+	}
+}
+
+// BOQItem is one line of the bill of quantities: one layer of one element.
+type BOQItem struct {
+	Element      string               `json:"element"`
+	Material     string               `json:"material"`
+	GrossArea    float64              `json:"gross_area"`    // m²
+	OpeningArea  float64              `json:"opening_area"`  // m²
+	NetArea      float64              `json:"net_area"`      // m²
+	Thickness    float64              `json:"thickness"`     // m
+	NetVolume    float64              `json:"net_volume"`    // m³, including corners
+	OrderVolume  float64              `json:"order_volume"`  // m³, including waste
+	MaterialCost constants.PriceRange `json:"material_cost"` // for OrderVolume
+	LabourCost   constants.PriceRange `json:"labour_cost"`
+	Total        constants.PriceRange `json:"total"`
+}
+
+type BillOfQuantities struct {
+	Items         []BOQItem            `json:"items"`
+	MaterialTotal constants.PriceRange `json:"material_total"`
+	LabourTotal   constants.PriceRange `json:"labour_total"`
+	Total         constants.PriceRange `json:"total"`
+}
+
+// CalculateTakeoff itemises material volumes and installation costs for the
+// given envelope. All costs are returned in the base currency.
+func CalculateTakeoff(elements []EnvelopeElement, p TakeoffParams) (BillOfQuantities, error) {
+	if p.WasteFactor < 0 {
+		return BillOfQuantities{}, errors.New("waste factor must not be negative")
+	}
+
+	zero := constants.PriceRange{Currency: constants.BaseCurrency}
+	boq := BillOfQuantities{MaterialTotal: zero, LabourTotal: zero, Total: zero}
+
+	for _, e := range elements {
+		if e.Width <= 0 || e.Height <= 0 {
+			return BillOfQuantities{}, fmt.Errorf("%s: dimensions must be greater than zero", e.Name)
+		}
+		net := e.NetArea()
+		if net < 0 {
+			return BillOfQuantities{}, fmt.Errorf("%s: openings exceed element area", e.Name)
+		}
+
+		// offset of the current layer from the inner face
+		offset := 0.0
+		for _, l := range e.Layers {
+			if l.Thickness <= 0 {
+				return BillOfQuantities{}, fmt.Errorf("%s: %s thickness must be greater than zero", e.Name, l.Material)
+			}
+			m, err := constants.GetMaterial(l.Material)
+			if err != nil {
+				return BillOfQuantities{}, fmt.Errorf("%s: %s: %w", e.Name, l.Material, err)
+			}
+
+			// each corner is shared by two walls, so every wall takes half of
+			// the L-shaped corner piece at both of its ends
+			outer := offset + l.Thickness
+			cornerVolume := float64(e.Corners) / 2 * (outer*outer - offset*offset) * e.Height
+			offset = outer
+
+			netVolume := net*l.Thickness + cornerVolume
+			orderVolume := netVolume * (1 + p.WasteFactor)
+
+			materialCost, err := m.Cost.Scale(orderVolume).Convert(constants.BaseCurrency)
+			if err != nil {
+				return BillOfQuantities{}, err
+			}
+
+			labourRate, ok := p.Labour[l.Material]
+			if !ok {
+				labourRate = p.LabourPerM2
+			}
+			labourCost, err := labourRate.Scale(net).Convert(constants.BaseCurrency)
+			if err != nil {
+				return BillOfQuantities{}, err
+			}
+
+			total, _ := materialCost.Add(labourCost)
+			boq.Items = append(boq.Items, BOQItem{
+				Element:      e.Name,
+				Material:     l.Material,
+				GrossArea:    e.GrossArea(),
+				OpeningArea:  e.GrossArea() - net,
+				NetArea:      net,
+				Thickness:    l.Thickness,
+				NetVolume:    netVolume,
+				OrderVolume:  orderVolume,
+				MaterialCost: materialCost,
+				LabourCost:   labourCost,
+				Total:        total,
+			})
+
+			boq.MaterialTotal, _ = boq.MaterialTotal.Add(materialCost)
+			boq.LabourTotal, _ = boq.LabourTotal.Add(labourCost)
+			boq.Total, _ = boq.Total.Add(total)
+		}
+	}
+
+	return boq, nil
+}
+
+// WriteCSV writes the bill of quantities as an itemised table with a totals row.
+func (b BillOfQuantities) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 3, 64) }
+	c := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+
+	header := []string{
+		"element", "material", "gross_area_m2", "opening_area_m2", "net_area_m2", "thickness_m",
+		"net_volume_m3", "order_volume_m3",
+		"material_min", "material_typical", "material_max",
+		"labour_min", "labour_typical", "labour_max",
+		"total_min", "total_typical", "total_max", "currency",
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, it := range b.Items {
+		row := []string{
+			it.Element, it.Material, f(it.GrossArea), f(it.OpeningArea), f(it.NetArea), f(it.Thickness),
+			f(it.NetVolume), f(it.OrderVolume),
+			c(it.MaterialCost.Min), c(it.MaterialCost.Typical), c(it.MaterialCost.Max),
+			c(it.LabourCost.Min), c(it.LabourCost.Typical), c(it.LabourCost.Max),
+			c(it.Total.Min), c(it.Total.Typical), c(it.Total.Max), it.Total.Currency,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	totals := []string{
+		"Total", "", "", "", "", "", "", "",
+		c(b.MaterialTotal.Min), c(b.MaterialTotal.Typical), c(b.MaterialTotal.Max),
+		c(b.LabourTotal.Min), c(b.LabourTotal.Typical), c(b.LabourTotal.Max),
+		c(b.Total.Min), c(b.Total.Typical), c(b.Total.Max), b.Total.Currency,
+	}
+	if err := cw.Write(totals); err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
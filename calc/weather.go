@@ -0,0 +1,28 @@
+package calc
+
+import "time"
+
+// Weather is a simulated day of outside conditions, one value per minute from
+// 05:00 like the temperature profile. Temp is always set, the other variables
+// only when Extended is true; sources such as weather files carry temperature
+// alone.
+type Weather struct {
+	Extended bool `json:"extended"`
+	// Start is 05:00 on the simulated day, zero for sources without a date.
+	Start time.Time `json:"start,omitzero"`
+
+	Temp      [840]float64 `json:"temp"`       // °C
+	Humidity  [840]float64 `json:"humidity"`   // relative, %
+	DewPoint  [840]float64 `json:"dew_point"`  // °C
+	Clouds    [840]float64 `json:"clouds"`     // cover, %
+	WindSpeed [840]float64 `json:"wind_speed"` // m/s
+	WindDeg   [840]float64 `json:"wind_deg"`   // direction the wind blows from, degrees
+	UVI       [840]float64 `json:"uvi"`        // UV index
+	Pressure  [840]float64 `json:"pressure"`   // sea level, hPa
+}
+
+// TemperatureOnly wraps a temperature profile for sources without the other
+// variables.
+func TemperatureOnly(temps [840]float64) Weather {
+	return Weather{Temp: temps}
+}
@@ -0,0 +1,259 @@
+package chartings
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"time"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/plotutil"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// ACOverlay is the compressor state at each point of the inside temperature.
+// The solver only cools between OnTime and OffTime, so the compressor state
+// outside that window is neither shaded nor counted.
+type ACOverlay struct {
+	Running      []bool
+	OnTime       int     // minutes after 05:00
+	OffTime      int     // minutes after 05:00
+	CoolingPower float64 // W, the sign is ignored
+}
+
+// cooling returns the running state masked by the operating window.
+func (ac ACOverlay) cooling() []bool {
+	on := make([]bool, len(ac.Running))
+	for i, running := range ac.Running {
+		on[i] = running && i >= ac.OnTime && i < ac.OffTime
+	}
+	return on
+}
+
+var compressorColor = color.NRGBA{R: 230, G: 120, B: 40, A: 45}
+
+// compressorShading shades the full height of the data area wherever the
+// compressor is running.
+type compressorShading struct {
+	x       []float64
+	running []bool
+}
+
+func (s compressorShading) Plot(c draw.Canvas, plt *plot.Plot) {
+	trX, _ := plt.Transforms(&c)
+	n := min(len(s.x), len(s.running))
+
+	for i := 0; i < n; i++ {
+		if !s.running[i] {
+			continue
+		}
+		start := i
+		for i < n && s.running[i] {
+			i++
+		}
+		// the last running sample lasts until the next sample
+		end := s.x[n-1]
+		if i < n {
+			end = s.x[i]
+		}
+
+		x0, x1 := trX(s.x[start]), trX(end)
+		c.FillPolygon(compressorColor, c.ClipPolygonXY([]vg.Point{
+			{X: x0, Y: c.Min.Y}, {X: x1, Y: c.Min.Y},
+			{X: x1, Y: c.Max.Y}, {X: x0, Y: c.Max.Y},
+		}))
+	}
+}
+
+func (s compressorShading) Thumbnail(c *draw.Canvas) {
+	c.FillPolygon(compressorColor, []vg.Point{
+		{X: c.Min.X, Y: c.Min.Y}, {X: c.Max.X, Y: c.Min.Y},
+		{X: c.Max.X, Y: c.Max.Y}, {X: c.Min.X, Y: c.Max.Y},
+	})
+}
+
+func addCompressorShading(p *plot.Plot, pts plotter.XYs, running []bool, legend bool) {
+	x := make([]float64, len(pts))
+	for i, pt := range pts {
+		x[i] = pt.X
+	}
+	s := compressorShading{x: x, running: running}
+	p.Add(s)
+	if legend {
+		p.Legend.Add("Compressor on", s)
+	}
+}
+
+// ACPlots returns the cooling power and cumulative energy panels for the
+// compressor state sampled at the x values of pts, which are in minutes.
+func ACPlots(pts plotter.XYs, ac ACOverlay, o Options) (*plot.Plot, *plot.Plot, error) {
+	n := min(len(pts), len(ac.Running))
+	if n == 0 {
+		return nil, nil, fmt.Errorf("no AC data to plot")
+	}
+	cooling := ac.cooling()
+	kw := math.Abs(ac.CoolingPower) / 1000
+	power, energy, total := acSeries(pts[:n], cooling[:n], kw)
+
+	// the panels are short, so the unit alone labels the y axis
+	panel := func(unit, legend string, xys plotter.XYs, c color.Color, step plotter.StepKind) (*plot.Plot, error) {
+		p := plot.New()
+		panelOptions := o
+		panelOptions.Title = ""
+		panelOptions.YLabel, panelOptions.YUnit = unit, ""
+		panelOptions.apply(p)
+
+		addCompressorShading(p, pts[:n], cooling[:n], false)
+		line, err := plotter.NewLine(xys)
+		if err != nil {
+			return nil, err
+		}
+		line.Color = c
+		line.StepStyle = step
+		p.Add(line)
+		p.Legend.Add(legend, line)
+		p.Y.Min = 0
+		p.Legend.Top = true
+		p.Legend.Left = true
+		return p, nil
+	}
+
+	powerPlot, err := panel("kW", "Cooling power", power, plotutil.Color(2), plotter.PostStep)
+	if err != nil {
+		return nil, nil, err
+	}
+	powerPlot.Y.Max = math.Max(kw*1.2, 0.1)
+
+	energyPlot, err := panel("kWh", fmt.Sprintf("Cumulative energy, %.2f kWh", total), energy, plotutil.Color(3), plotter.NoStep)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return powerPlot, energyPlot, nil
+}
+
+// acSeries returns the cooling power in kW and the energy used up to each
+// point in kWh, with the total.
+func acSeries(pts plotter.XYs, cooling []bool, kw float64) (plotter.XYs, plotter.XYs, float64) {
+	power := make(plotter.XYs, len(pts))
+	energy := make(plotter.XYs, len(pts))
+	total := 0.0
+	for i := range pts {
+		power[i].X, energy[i].X = pts[i].X, pts[i].X
+		if cooling[i] {
+			power[i].Y = kw
+		}
+		energy[i].Y = total
+		if i+1 < len(pts) {
+			total += power[i].Y * (pts[i+1].X - pts[i].X) / 60
+		}
+	}
+	return power, energy, total
+}
+
+// DrawTemperature draws the temperature chart onto c. With an AC overlay the
+// cooling power and cumulative energy panels are stacked below it on a
+// shared time axis.
+func DrawTemperature(c draw.Canvas, insidePts, outsidePts plotter.XYs, o Options) error {
+	p, err := TemperaturePlot(insidePts, outsidePts, o)
+	if err != nil {
+		return err
+	}
+	if o.AC == nil {
+		p.Draw(c)
+		return nil
+	}
+
+	power, energy, err := ACPlots(insidePts, *o.AC, o)
+	if err != nil {
+		return err
+	}
+
+	// only the bottom panel keeps its time axis labels
+	p.X.Label.Text = ""
+	power.X.Label.Text = ""
+	energy.X.Tick.Marker = p.X.Tick.Marker
+	for _, q := range []*plot.Plot{p, power} {
+		q.X.Tick.Marker = unlabelled{p.X.Tick.Marker}
+	}
+	for _, q := range []*plot.Plot{power, energy} {
+		q.X.Min, q.X.Max = p.X.Min, p.X.Max
+	}
+
+	drawStacked(c, []*plot.Plot{p, power, energy}, []float64{0.55, 0.2, 0.25})
+	return nil
+}
+
+// drawStacked draws plots top to bottom, each taking its weight of the
+// height, with the data areas aligned horizontally.
+func drawStacked(c draw.Canvas, plots []*plot.Plot, weights []float64) {
+	sum := 0.0
+	for _, w := range weights {
+		sum += w
+	}
+
+	canvases := make([]draw.Canvas, len(plots))
+	top := c.Max.Y
+	var left, right vg.Length
+	for i, p := range plots {
+		h := vg.Length(weights[i]/sum) * (c.Max.Y - c.Min.Y)
+		canvases[i] = draw.Crop(c, 0, 0, top-h-c.Min.Y, top-c.Max.Y)
+		top -= h
+
+		data := p.DataCanvas(canvases[i])
+		left = max(left, data.Min.X-canvases[i].Min.X)
+		right = max(right, canvases[i].Max.X-data.Max.X)
+	}
+
+	for i, p := range plots {
+		data := p.DataCanvas(canvases[i])
+		dc := draw.Crop(canvases[i],
+			left-(data.Min.X-canvases[i].Min.X), -(right - (canvases[i].Max.X - data.Max.X)), 0, 0)
+		p.Draw(dc)
+	}
+}
+
+// unlabelled keeps the tick positions of a Ticker but drops the labels.
+type unlabelled struct {
+	plot.Ticker
+}
+
+func (t unlabelled) Ticks(min, max float64) []plot.Tick {
+	ticks := t.Ticker.Ticks(min, max)
+	for i := range ticks {
+		ticks[i].Label = ""
+	}
+	return ticks
+}
+
+// ClockTicks labels a minute axis with the time of day, Start being the
+// time of day at minute zero.
+type ClockTicks struct {
+	Start time.Duration
+}
+
+func (t ClockTicks) Ticks(min, max float64) []plot.Tick {
+	// label every hour on short ranges, every second hour otherwise
+	labelEvery := 60.0
+	if max-min > 8*60 {
+		labelEvery = 120
+	}
+
+	start := t.Start.Minutes()
+	var ticks []plot.Tick
+	for m := math.Ceil((min+start)/60) * 60; m <= max+start; m += 60 {
+		tick := plot.Tick{Value: m - start}
+		if math.Mod(m, labelEvery) == 0 {
+			tick.Label = clock(m)
+		}
+		ticks = append(ticks, tick)
+	}
+	return ticks
+}
+
+func clock(minuteOfDay float64) string {
+	m := int(minuteOfDay) % (24 * 60)
+	return fmt.Sprintf("%02d:%02d", m/60, m%60)
+}
@@ -0,0 +1,45 @@
+package chartings
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/plot/plotter"
+)
+
+func TestACMaskWindow(t *testing.T) {
+	// the solver leaves the compressor on outside the window without cooling
+	pts := make(plotter.XYs, 840)
+	running := make([]bool, 840)
+	for i := range pts {
+		pts[i].X = float64(i)
+		running[i] = true
+	}
+	ac := ACOverlay{Running: running, OnTime: 240, OffTime: 600, CoolingPower: -2000}
+
+	cooling := ac.cooling()
+	for _, i := range []int{0, 239, 600, 839} {
+		if cooling[i] {
+			t.Errorf("minute %d outside the window counted as cooling", i)
+		}
+	}
+	if !cooling[240] || !cooling[599] {
+		t.Error("minutes inside the window not counted as cooling")
+	}
+
+	power, energy, total := acSeries(pts, cooling, math.Abs(ac.CoolingPower)/1000)
+	if power[239].Y != 0 || power[240].Y != 2 || power[600].Y != 0 {
+		t.Errorf("power %g, %g and %g kW around the window, want 0, 2 and 0", power[239].Y, power[240].Y, power[600].Y)
+	}
+	// 2 kW for the six hours of the window
+	if math.Abs(total-12) > 1e-9 || energy[240].Y != 0 || math.Abs(energy[839].Y-12) > 1e-9 {
+		t.Errorf("energy %g kWh, %g at the end, want 12 and nothing before 09:00", total, energy[839].Y)
+	}
+
+	if _, _, err := ACPlots(pts, ac, DefaultOptions()); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ACPlots(pts, ACOverlay{}, DefaultOptions()); err == nil {
+		t.Error("no compressor state should fail")
+	}
+}
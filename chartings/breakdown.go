@@ -0,0 +1,241 @@
+package chartings
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/plotutil"
+	"gonum.org/v1/plot/vg"
+)
+
+// Stack is one layer of a stacked chart, Values sharing the x values of the
+// chart.
+type Stack struct {
+	Name   string
+	Values []float64
+}
+
+// HeatFlowPlot stacks the heat gains of each layer above zero and the losses
+// below it, with the net flow drawn as a line. x is in minutes; the title,
+// fonts and time axis are taken from o.
+func HeatFlowPlot(x []float64, stacks []Stack, o Options) (*plot.Plot, error) {
+	if len(x) == 0 || len(stacks) == 0 {
+		return nil, fmt.Errorf("no data points to plot")
+	}
+	for _, s := range stacks {
+		if len(s.Values) != len(x) {
+			return nil, fmt.Errorf("%s: %d values for %d points", s.Name, len(s.Values), len(x))
+		}
+	}
+
+	p := plot.New()
+	o.YLabel, o.YUnit = "Heat gain", "W"
+	o.apply(p)
+
+	gains := make([]float64, len(x))
+	losses := make([]float64, len(x))
+	net := make(plotter.XYs, len(x))
+	for i, s := range stacks {
+		var pos, neg plotter.XYs
+		for j, v := range s.Values {
+			net[j].X = x[j]
+			net[j].Y += v
+			if v > 0 {
+				pos = append(pos, plotter.XY{X: x[j], Y: gains[j] + v})
+			} else {
+				pos = append(pos, plotter.XY{X: x[j], Y: gains[j]})
+			}
+			if v < 0 {
+				neg = append(neg, plotter.XY{X: x[j], Y: losses[j] + v})
+			} else {
+				neg = append(neg, plotter.XY{X: x[j], Y: losses[j]})
+			}
+		}
+
+		c := plotutil.Color(i)
+		var thumb *plotter.Polygon
+		for _, band := range []struct {
+			edge plotter.XYs
+			base []float64
+		}{{pos, gains}, {neg, losses}} {
+			poly, err := bandPolygon(band.edge, band.base)
+			if err != nil {
+				return nil, err
+			}
+			poly.Color = c
+			poly.LineStyle.Width = 0
+			p.Add(poly)
+			thumb = poly
+		}
+		p.Legend.Add(s.Name, thumb)
+
+		for j := range x {
+			gains[j] = pos[j].Y
+			losses[j] = neg[j].Y
+		}
+	}
+
+	line, err := plotter.NewLine(net)
+	if err != nil {
+		return nil, err
+	}
+	line.Color = color.Black
+	line.Width = vg.Points(1.5)
+	p.Add(line)
+	p.Legend.Add("Net", line)
+	p.Legend.Left = true
+
+	return p, nil
+}
+
+// bandPolygon closes the area between edge and base.
+func bandPolygon(edge plotter.XYs, base []float64) (*plotter.Polygon, error) {
+	ring := make(plotter.XYs, 0, 2*len(edge))
+	ring = append(ring, edge...)
+	for i := len(edge) - 1; i >= 0; i-- {
+		ring = append(ring, plotter.XY{X: edge[i].X, Y: base[i]})
+	}
+	return plotter.NewPolygon(ring)
+}
+
+// Resample averages stacks over bins of step minutes, which keeps short AC
+// cycles from dominating a day long chart. The returned x values are the bin
+// starts.
+func Resample(x []float64, stacks []Stack, step float64) ([]float64, []Stack) {
+	if len(x) == 0 || step <= 0 {
+		return x, stacks
+	}
+
+	var bins []float64
+	var index []int // bin of every point
+	for _, v := range x {
+		start := math.Floor(v/step) * step
+		if len(bins) == 0 || bins[len(bins)-1] != start {
+			bins = append(bins, start)
+		}
+		index = append(index, len(bins)-1)
+	}
+
+	out := make([]Stack, len(stacks))
+	for i, s := range stacks {
+		sum := make([]float64, len(bins))
+		count := make([]int, len(bins))
+		for j, v := range s.Values {
+			if j >= len(index) {
+				break
+			}
+			sum[index[j]] += v
+			count[index[j]]++
+		}
+		for b := range sum {
+			if count[b] > 0 {
+				sum[b] /= float64(count[b])
+			}
+		}
+		out[i] = Stack{Name: s.Name, Values: sum}
+	}
+	return bins, out
+}
+
+// WriteHeatFlow renders the stacked heat flow chart to w.
+func WriteHeatFlow(w io.Writer, x []float64, stacks []Stack, o Options) error {
+	p, err := HeatFlowPlot(x, stacks, o)
+	if err != nil {
+		return err
+	}
+	return Render(w, p, o)
+}
+
+// Bar is one cost item. When Min < Max the range is drawn as an error bar.
+type Bar struct {
+	Label string
+	Value float64
+	Min   float64
+	Max   float64
+}
+
+// costRanges adapts bars to plotter.NewYErrorBars.
+type costRanges struct {
+	plotter.XYs
+	plotter.YErrors
+}
+
+// CostPlot draws a labelled bar for every item; unit is the currency or
+// period shown on the y axis. The title and fonts are taken from o.
+func CostPlot(bars []Bar, unit string, o Options) (*plot.Plot, error) {
+	if len(bars) == 0 {
+		return nil, fmt.Errorf("no data points to plot")
+	}
+
+	p := plot.New()
+	o.XLabel, o.XUnit = "", ""
+	o.YLabel, o.YUnit = "Cost", unit
+	o.TimeOfDay = false
+	o.apply(p)
+
+	values := make(plotter.Values, len(bars))
+	labels := make([]string, len(bars))
+	var ranges costRanges
+	valueLabels := plotter.XYLabels{}
+	for i, b := range bars {
+		values[i] = b.Value
+		labels[i] = b.Label
+		top := b.Value
+		if b.Min < b.Max {
+			ranges.XYs = append(ranges.XYs, plotter.XY{X: float64(i), Y: b.Value})
+			ranges.YErrors = append(ranges.YErrors, struct{ Low, High float64 }{b.Value - b.Min, b.Max - b.Value})
+			top = b.Max
+		}
+		valueLabels.XYs = append(valueLabels.XYs, plotter.XY{X: float64(i), Y: top})
+		valueLabels.Labels = append(valueLabels.Labels, fmt.Sprintf("%.2f", b.Value))
+	}
+
+	chart, err := plotter.NewBarChart(values, vg.Points(28))
+	if err != nil {
+		return nil, err
+	}
+	chart.Color = plotutil.Color(0)
+	chart.LineStyle.Width = 0
+	p.Add(chart)
+	p.NominalX(labels...)
+
+	if len(ranges.XYs) > 0 {
+		errBars, err := plotter.NewYErrorBars(ranges)
+		if err != nil {
+			return nil, err
+		}
+		p.Add(errBars)
+	}
+
+	text, err := plotter.NewLabels(valueLabels)
+	if err != nil {
+		return nil, err
+	}
+	for i := range text.TextStyle {
+		text.TextStyle[i].Font = p.Legend.TextStyle.Font
+		text.TextStyle[i].Font.Size = vg.Points(8)
+		text.TextStyle[i].XAlign = -0.5
+	}
+	text.Offset = vg.Point{Y: vg.Points(3)}
+	p.Add(text)
+
+	p.Y.Min = 0
+	_, _, _, ymax := plotter.XYRange(valueLabels)
+	// room for the value labels
+	p.Y.Max = ymax * 1.15
+
+	return p, nil
+}
+
+// WriteCost renders the cost bar chart to w.
+func WriteCost(w io.Writer, bars []Bar, unit string, o Options) error {
+	p, err := CostPlot(bars, unit, o)
+	if err != nil {
+		return err
+	}
+	return Render(w, p, o)
+}
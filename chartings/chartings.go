@@ -3,54 +3,252 @@ package chartings
 import (
 	"bytes"
 	"fmt"
+	"image/color"
+	"io"
+	"math"
 
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
 	"gonum.org/v1/plot/plotutil"
 	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
 )
 
+// Plot renders the inside and outside temperature as a PNG with the default
+// options.
 func Plot(insidePts, outsidePts plotter.XYs) (*bytes.Buffer, error) {
+	buf := new(bytes.Buffer)
+	if err := WriteTemperature(buf, insidePts, outsidePts, DefaultOptions()); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// WriteTemperature renders the temperature chart to w.
+func WriteTemperature(w io.Writer, insidePts, outsidePts plotter.XYs, o Options) error {
+	return writeCanvas(w, o, func(c draw.Canvas) error {
+		return DrawTemperature(c, insidePts, outsidePts, o)
+	})
+}
+
+// TemperaturePlot builds the inside vs outside temperature plot without
+// rendering it.
+func TemperaturePlot(insidePts, outsidePts plotter.XYs, o Options) (*plot.Plot, error) {
 	if len(insidePts) == 0 || len(outsidePts) == 0 {
 		return nil, fmt.Errorf("no data points to plot")
 	}
 
-	// Create a new plot.
 	p := plot.New()
-	p.Title.Text = "Inside vs Outside Temperature"
-	p.X.Label.Text = "Time (minutes)"
-	p.Y.Label.Text = "Temperature (°C)"
-	p.Legend.TextStyle.Font.Typeface = "Ubuntu-Regular"
-	p.Title.TextStyle.Font.Typeface = "Ubuntu-Bold"
+	o.apply(p)
+
+	// the band goes first so the lines are drawn over it
+	if o.SetPoint != nil {
+		if err := addSetPointBand(p, insidePts, *o.SetPoint, o.SetPointBand); err != nil {
+			return nil, err
+		}
+	}
+
+	if o.AC != nil {
+		addCompressorShading(p, insidePts, o.AC.cooling(), true)
+	}
 
-	// Add the inside and outside temperature lines.
 	err := plotutil.AddLines(p,
 		"Inside", insidePts,
 		"Outside", outsidePts)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	// Max and min temperature values
-	maxInsideTemp := -1000.0
-	for _, pt := range insidePts {
-		if pt.Y > maxInsideTemp {
-			maxInsideTemp = pt.Y
+	p.Legend.Top = true
+	p.Legend.Left = true
+
+	if o.Annotate {
+		if err := addExtremes(p, insidePts, o.YUnit); err != nil {
+			return nil, err
 		}
 	}
 
-	// Save the plot to a PNG file.
-	if err := p.Save(10*vg.Inch, 4*vg.Inch, "temperature_plot.png"); err != nil {
-		panic(err)
+	return p, nil
+}
+
+var setPointColor = color.RGBA{R: 80, G: 160, B: 230, A: 255}
+
+func addSetPointBand(p *plot.Plot, pts plotter.XYs, setPoint, band float64) error {
+	xmin, xmax, _, _ := plotter.XYRange(pts)
+
+	if band > 0 {
+		rect, err := plotter.NewPolygon(plotter.XYs{
+			{X: xmin, Y: setPoint - band}, {X: xmax, Y: setPoint - band},
+			{X: xmax, Y: setPoint + band}, {X: xmin, Y: setPoint + band},
+		})
+		if err != nil {
+			return err
+		}
+		rect.Color = color.NRGBA{R: 80, G: 160, B: 230, A: 50}
+		rect.LineStyle.Width = 0
+		p.Add(rect)
 	}
 
-	// Render to *bytes.Buffer
-	buf := new(bytes.Buffer)
-	wt, err := p.WriterTo(6*vg.Inch, 3*vg.Inch, "png")
+	line, err := plotter.NewLine(plotter.XYs{{X: xmin, Y: setPoint}, {X: xmax, Y: setPoint}})
 	if err != nil {
-		panic(err)
+		return err
 	}
-	wt.WriteTo(buf)
+	line.Color = setPointColor
+	line.Dashes = []vg.Length{vg.Points(4), vg.Points(3)}
+	p.Add(line)
+	p.Legend.Add("Set point", line)
+	return nil
+}
 
-	return buf, nil
+// addExtremes marks and labels the lowest and highest point of pts.
+func addExtremes(p *plot.Plot, pts plotter.XYs, unit string) error {
+	lo, hi := 0, 0
+	for i, pt := range pts {
+		if pt.Y < pts[lo].Y {
+			lo = i
+		}
+		if pt.Y > pts[hi].Y {
+			hi = i
+		}
+	}
+
+	marks := plotter.XYs{pts[hi], pts[lo]}
+	scatter, err := plotter.NewScatter(marks)
+	if err != nil {
+		return err
+	}
+	scatter.GlyphStyle.Shape = draw.CircleGlyph{}
+	scatter.GlyphStyle.Radius = vg.Points(2.5)
+	p.Add(scatter)
+
+	labels, err := plotter.NewLabels(plotter.XYLabels{
+		XYs: marks,
+		Labels: []string{
+			fmt.Sprintf("max %.1f %s", pts[hi].Y, unit),
+			fmt.Sprintf("min %.1f %s", pts[lo].Y, unit),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	labels.Offset = vg.Point{X: vg.Points(4), Y: vg.Points(3)}
+	for i := range labels.TextStyle {
+		labels.TextStyle[i].Font = p.Legend.TextStyle.Font
+		labels.TextStyle[i].Font.Size = vg.Points(8)
+	}
+	p.Add(labels)
+	return nil
+}
+
+type Series struct {
+	Name   string
+	Points plotter.XYs
+}
+
+// PlotComparison overlays the inside temperature of several scenarios on the shared outside temperature.
+func PlotComparison(outsidePts plotter.XYs, series []Series) (*bytes.Buffer, error) {
+	p, err := ComparisonPlot("Scenario Comparison", outsidePts, series)
+	if err != nil {
+		return nil, err
+	}
+
+	return renderPNG(p, 6*vg.Inch, 3*vg.Inch)
+}
+
+// ComparisonPlot builds the overlaid temperature plot without rendering it,
+// so callers can draw it onto their own canvas.
+func ComparisonPlot(title string, outsidePts plotter.XYs, series []Series) (*plot.Plot, error) {
+	if len(outsidePts) == 0 || len(series) == 0 {
+		return nil, fmt.Errorf("no data points to plot")
+	}
+
+	p := plot.New()
+	o := DefaultOptions()
+	o.Title = title
+	o.apply(p)
+
+	lines := []interface{}{"Outside", outsidePts}
+	for _, s := range series {
+		lines = append(lines, s.Name, s.Points)
+	}
+	if err := plotutil.AddLines(p, lines...); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// XYs pairs two equal length slices into plot points.
+func XYs(x, y []float64) plotter.XYs {
+	n := min(len(x), len(y))
+	pts := make(plotter.XYs, n)
+	for i := range n {
+		pts[i].X = x[i]
+		pts[i].Y = y[i]
+	}
+	return pts
+}
+
+// TornadoBar is the output range of one input, Low and High are the output
+// values at the input's low and high setting.
+type TornadoBar struct {
+	Label string
+	Low   float64
+	High  float64
+}
+
+// PlotTornado draws bars sorted as given, the first bar at the top.
+func PlotTornado(title, unit string, baseline float64, bars []TornadoBar) (*bytes.Buffer, error) {
+	if len(bars) == 0 {
+		return nil, fmt.Errorf("no data points to plot")
+	}
+
+	p := plot.New()
+	p.Title.Text = title
+	p.X.Label.Text = unit
+
+	labels := make([]string, len(bars))
+	lowColor := plotutil.Color(0)
+	highColor := plotutil.Color(1)
+	for i, b := range bars {
+		y := float64(len(bars) - 1 - i)
+		labels[len(bars)-1-i] = b.Label
+
+		sides := []struct {
+			value float64
+			color color.Color
+		}{{b.Low, lowColor}, {b.High, highColor}}
+		// draw the longer bar first so a shorter one on the same side stays visible
+		if math.Abs(b.Low-baseline) < math.Abs(b.High-baseline) {
+			sides[0], sides[1] = sides[1], sides[0]
+		}
+		for _, side := range sides {
+			rect, err := plotter.NewPolygon(plotter.XYs{
+				{X: baseline, Y: y - 0.35}, {X: side.value, Y: y - 0.35},
+				{X: side.value, Y: y + 0.35}, {X: baseline, Y: y + 0.35},
+			})
+			if err != nil {
+				return nil, err
+			}
+			rect.Color = side.color
+			rect.LineStyle.Width = 0
+			p.Add(rect)
+		}
+	}
+	p.NominalY(labels...)
+	// room for the legend above the first bar
+	p.Y.Max = float64(len(bars)) + 0.3
+
+	line, err := plotter.NewLine(plotter.XYs{{X: baseline, Y: -0.5}, {X: baseline, Y: float64(len(bars)) - 0.5}})
+	if err != nil {
+		return nil, err
+	}
+	line.Color = color.Black
+	p.Add(line)
+
+	p.Legend.Add("Low input", &plotter.Polygon{Color: lowColor})
+	p.Legend.Add("High input", &plotter.Polygon{Color: highColor})
+	p.Legend.Top = true
+
+	return renderPNG(p, 6*vg.Inch, vg.Length(1+0.4*float64(len(bars)))*vg.Inch)
 }
@@ -0,0 +1,153 @@
+package chartings
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/palette/moreland"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// DayProfile is the inside temperature of one simulated day, Minutes being
+// relative to the day start of the chart options.
+type DayProfile struct {
+	Label   string
+	Minutes []float64
+	Temps   []float64
+}
+
+// hourGrid holds hourly means with days as rows and hours of day as columns.
+type hourGrid struct {
+	firstHour int
+	z         [][]float64 // [day][hour]
+}
+
+func (g hourGrid) Dims() (c, r int)   { return len(g.z[0]), len(g.z) }
+func (g hourGrid) Z(c, r int) float64 { return g.z[r][c] }
+func (g hourGrid) X(c int) float64    { return float64(g.firstHour+c) + 0.5 }
+func (g hourGrid) Y(r int) float64    { return float64(r) }
+
+// hourly averages every day into the hours of day covered by any of them.
+// Hours without samples are NaN.
+func hourly(days []DayProfile, dayStartMinutes float64) (hourGrid, error) {
+	first, last := math.MaxInt, math.MinInt
+	for _, d := range days {
+		if len(d.Minutes) != len(d.Temps) {
+			return hourGrid{}, fmt.Errorf("%s: %d minutes for %d temperatures", d.Label, len(d.Minutes), len(d.Temps))
+		}
+		for _, m := range d.Minutes {
+			h := int(math.Floor((m + dayStartMinutes) / 60))
+			first, last = min(first, h), max(last, h)
+		}
+	}
+	if first > last {
+		return hourGrid{}, fmt.Errorf("no data points to plot")
+	}
+
+	g := hourGrid{firstHour: first, z: make([][]float64, len(days))}
+	for i, d := range days {
+		sum := make([]float64, last-first+1)
+		count := make([]int, len(sum))
+		for j, m := range d.Minutes {
+			h := int(math.Floor((m+dayStartMinutes)/60)) - first
+			sum[h] += d.Temps[j]
+			count[h]++
+		}
+		g.z[i] = make([]float64, len(sum))
+		for h := range sum {
+			g.z[i][h] = math.NaN()
+			if count[h] > 0 {
+				g.z[i][h] = sum[h] / float64(count[h])
+			}
+		}
+	}
+	return g, nil
+}
+
+type hourTicks struct{}
+
+func (hourTicks) Ticks(min, max float64) []plot.Tick {
+	var ticks []plot.Tick
+	for h := math.Ceil(min); h <= max; h++ {
+		tick := plot.Tick{Value: h}
+		if int(h)%2 == 0 {
+			tick.Label = fmt.Sprintf("%02d:00", int(h)%24)
+		}
+		ticks = append(ticks, tick)
+	}
+	return ticks
+}
+
+// DrawHeatmap draws a day by hour heatmap of the hourly mean inside
+// temperature with a colour scale in °C on the right.
+func DrawHeatmap(c draw.Canvas, days []DayProfile, o Options) error {
+	if len(days) == 0 {
+		return fmt.Errorf("no data points to plot")
+	}
+	g, err := hourly(days, o.DayStart.Minutes())
+	if err != nil {
+		return err
+	}
+
+	cm := moreland.SmoothBlueRed()
+	heat := plotter.NewHeatMap(g, cm.Palette(255))
+	heat.NaN = nil
+	if heat.Min == heat.Max {
+		heat.Min, heat.Max = heat.Min-0.5, heat.Max+0.5
+	}
+	cm.SetMin(heat.Min)
+	cm.SetMax(heat.Max)
+
+	p := plot.New()
+	o.XLabel, o.XUnit = "Hour of day", ""
+	o.YLabel, o.YUnit = "", ""
+	o.TimeOfDay = false
+	o.apply(p)
+	p.Add(heat)
+	p.X.Tick.Marker = hourTicks{}
+
+	labels := make([]string, len(days))
+	for i, d := range days {
+		labels[i] = d.Label
+	}
+	p.NominalY(labels...)
+
+	scale := plot.New()
+	scaleOptions := o
+	scaleOptions.Title = ""
+	scaleOptions.XLabel, scaleOptions.YLabel = "", ""
+	scaleOptions.XUnit, scaleOptions.YUnit = "", ""
+	scaleOptions.apply(scale)
+	scale.Add(&plotter.ColorBar{ColorMap: cm, Vertical: true})
+	scale.HideX()
+
+	// the title spans the heatmap and its scale
+	if p.Title.Text != "" {
+		sty := p.Title.TextStyle
+		sty.XAlign, sty.YAlign = draw.XCenter, draw.YTop
+		c.FillText(sty, vg.Point{X: (c.Min.X + c.Max.X) / 2, Y: c.Max.Y}, p.Title.Text)
+		c = draw.Crop(c, 0, 0, 0, -(sty.Height(p.Title.Text) + 2*p.Title.Padding))
+		p.Title.Text = ""
+	}
+
+	// the colour scale takes a fixed strip on the right, aligned vertically
+	// with the data area of the heatmap
+	width := 0.7 * vg.Inch
+	left := draw.Crop(c, 0, -width, 0, 0)
+	right := draw.Crop(c, c.Max.X-c.Min.X-width, 0, 0, 0)
+	data := p.DataCanvas(left)
+	p.Draw(left)
+	scale.Draw(draw.Crop(right, 0, 0, data.Min.Y-right.Min.Y, data.Max.Y-right.Max.Y))
+	return nil
+}
+
+// WriteHeatmap renders the day by hour heatmap to w.
+func WriteHeatmap(w io.Writer, days []DayProfile, o Options) error {
+	return writeCanvas(w, o, func(c draw.Canvas) error {
+		return DrawHeatmap(c, days, o)
+	})
+}
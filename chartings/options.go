@@ -0,0 +1,191 @@
+package chartings
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	xfont "golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/font"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+	FormatPDF = "pdf"
+)
+
+// Options controls how a chart is drawn and encoded.
+type Options struct {
+	Width  vg.Length
+	Height vg.Length
+	Format string // png, svg or pdf
+
+	Title  string
+	XLabel string
+	XUnit  string
+	YLabel string
+	YUnit  string
+
+	// Fonts are tried in order, the first one known to the font cache is
+	// used. Falls back to the plot default when none is available.
+	Fonts []font.Font
+
+	// SetPoint shades SetPoint ± SetPointBand when not nil.
+	SetPoint     *float64
+	SetPointBand float64
+
+	// Annotate marks the minimum and maximum inside temperature.
+	Annotate bool
+
+	// AC shades the compressor-on intervals and adds cooling power and
+	// cumulative energy panels when not nil.
+	AC *ACOverlay
+
+	// TimeOfDay labels the time axis with clock times, DayStart being the
+	// time of day at minute zero.
+	TimeOfDay bool
+	DayStart  time.Duration
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Width:        6 * vg.Inch,
+		Height:       3 * vg.Inch,
+		Format:       FormatPNG,
+		Title:        "Inside vs Outside Temperature",
+		XLabel:       "Time of day",
+		YLabel:       "Temperature",
+		YUnit:        "°C",
+		Fonts:        []font.Font{{Typeface: "Ubuntu"}, {Typeface: "Liberation", Variant: "Sans"}},
+		SetPointBand: 1.5,
+		Annotate:     true,
+		TimeOfDay:    true,
+		DayStart:     5 * time.Hour, // the simulation starts at 05:00
+	}
+}
+
+func (o Options) validate() error {
+	if o.Width <= 0 || o.Height <= 0 {
+		return fmt.Errorf("chart size must be greater than zero")
+	}
+	switch o.Format {
+	case FormatPNG, FormatSVG, FormatPDF:
+		return nil
+	}
+	return fmt.Errorf("unsupported chart format %q", o.Format)
+}
+
+// FormatFromPath returns the chart format matching a file extension, PNG
+// when the extension is unknown.
+func FormatFromPath(path string) string {
+	i := strings.LastIndex(path, ".")
+	if i < 0 {
+		return FormatPNG
+	}
+	switch ext := strings.ToLower(path[i+1:]); ext {
+	case FormatSVG, FormatPDF:
+		return ext
+	}
+	return FormatPNG
+}
+
+// LoadFont registers a TrueType or OpenType file under typeface so Options
+// can refer to it.
+func LoadFont(typeface font.Typeface, path string) error {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	face, err := opentype.Parse(buf)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	font.DefaultCache.Add(font.Collection{{Font: font.Font{Typeface: typeface}, Face: face}})
+	return nil
+}
+
+// resolveFont returns the first of fonts present in the font cache.
+func resolveFont(fonts []font.Font) (font.Font, bool) {
+	for _, f := range fonts {
+		if font.DefaultCache.Has(f) {
+			return f, true
+		}
+	}
+	return font.Font{}, false
+}
+
+func axisLabel(label, unit string) string {
+	if unit == "" {
+		return label
+	}
+	return label + " (" + unit + ")"
+}
+
+// apply sets the title, axis labels and fonts of p.
+func (o Options) apply(p *plot.Plot) {
+	p.Title.Text = o.Title
+	p.X.Label.Text = axisLabel(o.XLabel, o.XUnit)
+	p.Y.Label.Text = axisLabel(o.YLabel, o.YUnit)
+	if o.TimeOfDay {
+		p.X.Tick.Marker = ClockTicks{Start: o.DayStart}
+	}
+
+	f, ok := resolveFont(o.Fonts)
+	if !ok {
+		return
+	}
+	for _, sty := range []*font.Font{
+		&p.Title.TextStyle.Font,
+		&p.X.Label.TextStyle.Font, &p.Y.Label.TextStyle.Font,
+		&p.X.Tick.Label.Font, &p.Y.Tick.Label.Font,
+		&p.Legend.TextStyle.Font,
+	} {
+		sty.Typeface = f.Typeface
+		sty.Variant = f.Variant
+	}
+	// the cache falls back to the regular face when there is no bold one
+	p.Title.TextStyle.Font.Weight = xfont.WeightBold
+}
+
+// Render encodes p to w with the size and format of o.
+func Render(w io.Writer, p *plot.Plot, o Options) error {
+	return writeCanvas(w, o, func(c draw.Canvas) error {
+		p.Draw(c)
+		return nil
+	})
+}
+
+// writeCanvas encodes whatever drawFn draws to w with the size and format
+// of o.
+func writeCanvas(w io.Writer, o Options, drawFn func(c draw.Canvas) error) error {
+	if err := o.validate(); err != nil {
+		return err
+	}
+	c, err := draw.NewFormattedCanvas(o.Width, o.Height, o.Format)
+	if err != nil {
+		return err
+	}
+	if err := drawFn(draw.New(c)); err != nil {
+		return err
+	}
+	_, err = c.WriteTo(w)
+	return err
+}
+
+// renderPNG encodes p as a PNG of the given size into a new buffer.
+func renderPNG(p *plot.Plot, width, height vg.Length) (*bytes.Buffer, error) {
+	o := Options{Width: width, Height: height, Format: FormatPNG}
+	buf := new(bytes.Buffer)
+	if err := Render(buf, p, o); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
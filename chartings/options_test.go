@@ -0,0 +1,27 @@
+package chartings
+
+import (
+	"testing"
+
+	xfont "golang.org/x/image/font"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/font"
+)
+
+func TestApplyFonts(t *testing.T) {
+	o := DefaultOptions()
+	o.Fonts = []font.Font{{Typeface: "Missing"}, {Typeface: "Liberation", Variant: "Sans"}}
+	p := plot.New()
+	o.apply(p)
+
+	title := p.Title.TextStyle.Font
+	if title.Typeface != "Liberation" || title.Variant != "Sans" || title.Weight != xfont.WeightBold {
+		t.Errorf("title font %+v, want bold Liberation Sans", title)
+	}
+	if face := font.DefaultCache.Lookup(title, 12); face.Face == nil || face.Name() != "LiberationSans-Bold" {
+		t.Errorf("title face %q, want the bold face", face.Name())
+	}
+	if label := p.X.Label.TextStyle.Font; label.Typeface != "Liberation" || label.Weight != xfont.WeightNormal {
+		t.Errorf("axis label font %+v, want regular Liberation Sans", label)
+	}
+}
@@ -0,0 +1,55 @@
+package chartings
+
+import (
+	"heat-transfer/calc"
+	"heat-transfer/scenario"
+)
+
+// chart kinds that can be drawn from a simulation result
+const (
+	ChartTemperature = "temperature"
+	ChartHeatFlow    = "heatflow"
+	ChartCost        = "cost"
+	ChartMaterial    = "material"
+	ChartHeatmap     = "heatmap"
+)
+
+// Titles are the default titles of the chart kinds.
+var Titles = map[string]string{
+	ChartTemperature: "Inside vs Outside Temperature",
+	ChartHeatFlow:    "Heat Flow by Element",
+	ChartCost:        "Monthly AC Electricity Cost",
+	ChartMaterial:    "Material and Installation Cost",
+	ChartHeatmap:     "Inside Temperature (°C) by Day and Hour",
+}
+
+// HeatFlowStacks returns the heat flows of r by element and load type.
+func HeatFlowStacks(r scenario.Result) []Stack {
+	flows := r.HeatFlows()
+	stacks := make([]Stack, len(flows))
+	for i, f := range flows {
+		stacks[i] = Stack{Name: f.Name(), Values: f.Watts}
+	}
+	return stacks
+}
+
+// ACCostBars itemises the monthly AC electricity cost.
+func ACCostBars(c calc.ACCostBreakdown) []Bar {
+	return []Bar{
+		{Label: "Energy", Value: c.EnergyCharge},
+		{Label: "Ft", Value: c.FtCharge},
+		{Label: "Service", Value: c.ServiceCharge},
+		{Label: "VAT", Value: c.VAT},
+		{Label: "Total", Value: c.Total},
+	}
+}
+
+// BOQCostBars shows the material, labour and total cost of a bill of
+// quantities with their min to max range.
+func BOQCostBars(b calc.BillOfQuantities) []Bar {
+	return []Bar{
+		{Label: "Material", Value: b.MaterialTotal.Typical, Min: b.MaterialTotal.Min, Max: b.MaterialTotal.Max},
+		{Label: "Labour", Value: b.LabourTotal.Typical, Min: b.LabourTotal.Min, Max: b.LabourTotal.Max},
+		{Label: "Total", Value: b.Total.Typical, Min: b.Total.Min, Max: b.Total.Max},
+	}
+}
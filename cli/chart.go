@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"heat-transfer/chartings"
+	"heat-transfer/scenario"
+	weatherdata "heat-transfer/weatherData"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gonum.org/v1/plot/font"
+	"gonum.org/v1/plot/vg"
+)
+
+func init() {
+	register("chart", "simulate a scenario and render a chart", runChart)
+}
+
+func runChart(args []string) error {
+	o := chartings.DefaultOptions()
+
+	fs := flag.NewFlagSet("chart", flag.ContinueOnError)
+	var wf weatherFlags
+	wf.register(fs)
+	basePath := fs.String("base", "", "JSON file with the scenario")
+	kind := fs.String("type", "temperature", "temperature, heatflow, cost, material or heatmap")
+	step := fs.Float64("step", 15, "minutes averaged per point of the heat flow chart")
+	days := fs.String("days", "", "comma separated weather CSV files, one per day, for the heatmap")
+	outPath := fs.String("out", "chart.png", "output file, the format follows the extension (png, svg or pdf)")
+	format := fs.String("format", "", "png, svg or pdf, overrides the -out extension")
+	width := fs.Float64("width", 6, "width in inches")
+	height := fs.Float64("height", 0, "height in inches, defaults to 3 or 5 with AC panels")
+	title := fs.String("title", "", "chart title, defaults to one matching -type")
+	fontPath := fs.String("font", "", "TTF/OTF file used for all text, falls back to the built-in fonts")
+	fs.BoolVar(&o.Annotate, "annotate", o.Annotate, "mark the minimum and maximum inside temperature")
+	band := fs.Bool("setpoint", true, "shade the AC set point band when AC is enabled")
+	acPanels := fs.Bool("ac", true, "shade compressor-on intervals and add power and energy panels when AC is enabled")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *basePath == "" {
+		return errors.New("-base is required")
+	}
+	list, err := scenario.LoadScenarios(*basePath)
+	if err != nil {
+		return err
+	}
+
+	o.Title = *title
+	if o.Title == "" {
+		var ok bool
+		if o.Title, ok = chartings.Titles[*kind]; !ok {
+			return fmt.Errorf("unknown chart type %q", *kind)
+		}
+	}
+	o.Width = vg.Length(*width) * vg.Inch
+	o.Format = *format
+	if o.Format == "" {
+		o.Format = chartings.FormatFromPath(*outPath)
+	}
+	if *fontPath != "" {
+		if err := chartings.LoadFont("Custom", *fontPath); err != nil {
+			return err
+		}
+		o.Fonts = append([]font.Font{{Typeface: "Custom"}}, o.Fonts...)
+	}
+	if *height > 0 {
+		o.Height = vg.Length(*height) * vg.Inch
+	}
+
+	if *kind == chartings.ChartHeatmap {
+		if err := wf.interp.apply(); err != nil {
+			return err
+		}
+		if err := wf.quality.apply(); err != nil {
+			return err
+		}
+		return writeHeatmap(list[0], *days, *outPath, o)
+	}
+
+	outside, err := wf.load()
+	if err != nil {
+		return err
+	}
+
+	r, err := scenario.Run(list[0], outside)
+	if err != nil {
+		return err
+	}
+
+	var write func(w io.Writer) error
+	switch *kind {
+	case chartings.ChartHeatFlow:
+		write = func(w io.Writer) error {
+			x, stacks := chartings.Resample(r.TimeMinutes, chartings.HeatFlowStacks(r), *step)
+			return chartings.WriteHeatFlow(w, x, stacks, o)
+		}
+	case chartings.ChartCost:
+		write = func(w io.Writer) error {
+			return chartings.WriteCost(w, chartings.ACCostBars(r.ACCost), "THB/month", o)
+		}
+	case chartings.ChartMaterial:
+		write = func(w io.Writer) error {
+			return chartings.WriteCost(w, chartings.BOQCostBars(r.BOQ), "THB", o)
+		}
+	default:
+		if ac := r.Scenario.AC; ac != nil && ac.Enabled {
+			if *band {
+				o.SetPoint = &ac.SetTemp
+			}
+			if *acPanels {
+				o.AC = &chartings.ACOverlay{Running: r.ACRunning, OnTime: ac.OnTime, OffTime: ac.OffTime, CoolingPower: ac.CoolingPower}
+				if *height <= 0 {
+					o.Height = 5 * vg.Inch
+				}
+			}
+		}
+		write = func(w io.Writer) error {
+			return chartings.WriteTemperature(w,
+				chartings.XYs(r.TimeMinutes, r.InsideTemps),
+				chartings.XYs(r.TimeMinutes, r.OutsideTemps), o)
+		}
+	}
+
+	return writeFile(*outPath, write)
+}
+
+// writeHeatmap simulates s once for every weather file in days.
+func writeHeatmap(s scenario.Scenario, days, outPath string, o chartings.Options) error {
+	if days == "" {
+		return errors.New("-days is required for the heatmap")
+	}
+
+	var profiles []chartings.DayProfile
+	for _, path := range strings.Split(days, ",") {
+		path = strings.TrimSpace(path)
+		outside, err := weatherdata.GetTemperatureFromFile(path)
+		if err != nil {
+			return err
+		}
+		r, err := scenario.Run(s, outside)
+		if err != nil {
+			return err
+		}
+		profiles = append(profiles, chartings.DayProfile{
+			Label:   strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+			Minutes: r.TimeMinutes,
+			Temps:   r.InsideTemps,
+		})
+	}
+
+	if height := vg.Length(1.2+0.25*float64(len(profiles))) * vg.Inch; o.Height < height {
+		o.Height = height
+	}
+	return writeFile(outPath, func(w io.Writer) error {
+		return chartings.WriteHeatmap(w, profiles, o)
+	})
+}
@@ -0,0 +1,249 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"heat-transfer/calc"
+	"heat-transfer/export"
+	freader "heat-transfer/fReader"
+	"heat-transfer/interop"
+	"heat-transfer/quality"
+	weatherdata "heat-transfer/weatherData"
+	"io"
+	"os"
+	"time"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands []command
+
+func register(name, usage string, run func(args []string) error) {
+	commands = append(commands, command{name: name, usage: usage, run: run})
+}
+
+// Run executes a command line subcommand, args excludes the program name.
+func Run(args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(os.Stdout)
+		return nil
+	}
+
+	for _, c := range commands {
+		if c.name == args[0] {
+			err := c.run(args[1:])
+			if errors.Is(err, flag.ErrHelp) {
+				return nil
+			}
+			return err
+		}
+	}
+
+	printUsage(os.Stderr)
+	return fmt.Errorf("unknown command: %s", args[0])
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: heat-transfer [command] [flags]")
+	fmt.Fprintln(w, "\nwithout a command the GUI is started\n\ncommands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-12s %s\n", c.name, c.usage)
+	}
+}
+
+// weatherFlags selects the outside temperature source shared by every command.
+type weatherFlags struct {
+	location  string
+	file      string
+	tokenPath string
+	cacheDir  string
+	history   string
+	provider  string
+	interp    interpolationFlags
+	quality   qualityFlags
+}
+
+func (wf *weatherFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&wf.location, "location", "", "city to fetch the forecast for, e.g. \"Khon Kaen, TH\"")
+	fs.StringVar(&wf.file, "weather", "", "CSV file of hourly outside temperatures from 05:00 to 19:00")
+	fs.StringVar(&wf.tokenPath, "token", "./token", "OpenWeatherMap API token file")
+	fs.StringVar(&wf.provider, "provider", "", "weather provider for -location, openweathermap or openmeteo; defaults to openweathermap when the token file exists")
+	fs.StringVar(&wf.history, "history", "", "fetch the observed weather of this past day (YYYY-MM-DD) for -location instead of the forecast")
+	fs.StringVar(&wf.cacheDir, "cache-dir", weatherdata.DefaultCacheDir(), "directory for cached API responses, empty disables the cache")
+	wf.interp.register(fs)
+	wf.quality.register(fs)
+}
+
+func (wf *weatherFlags) load() ([840]float64, error) {
+	w, err := wf.loadWeather()
+	return w.Temp, err
+}
+
+// loadWeather is load with every variable the source provides.
+func (wf *weatherFlags) loadWeather() (calc.Weather, error) {
+	if err := wf.interp.apply(); err != nil {
+		return calc.Weather{}, err
+	}
+	if err := wf.quality.apply(); err != nil {
+		return calc.Weather{}, err
+	}
+	switch {
+	case wf.file != "":
+		temps, err := weatherdata.GetTemperatureFromFile(wf.file)
+		return calc.TemperatureOnly(temps), err
+	case wf.location != "":
+		p, err := newProvider(wf.provider, wf.tokenPath)
+		if err != nil {
+			return calc.Weather{}, err
+		}
+		weatherdata.DefaultCache = weatherdata.NewCache(wf.cacheDir)
+		weatherdata.ReportCache = reportCache
+		if wf.history != "" {
+			// noon UTC is on the same date from UTC-11 to UTC+11
+			day, err := time.Parse(time.DateOnly, wf.history)
+			if err != nil {
+				return calc.Weather{}, fmt.Errorf("invalid -history: %w", err)
+			}
+			return p.Historical(wf.location, day.Add(12*time.Hour))
+		}
+		return p.Forecast(wf.location)
+	default:
+		return calc.Weather{}, errors.New("either -weather or -location is required")
+	}
+}
+
+// dateUsage is the help of the -date flag of commands that timestamp results.
+const dateUsage = "day of the -weather file used for timestamps (YYYY-MM-DD), defaults to today; -location uses the day it simulates"
+
+// checkDate refuses -date for weather that has a day of its own, before
+// anything is fetched.
+func (wf *weatherFlags) checkDate(date string) error {
+	if date != "" && wf.file == "" {
+		return errors.New("-date only applies to -weather, -location uses the forecast or -history day")
+	}
+	return nil
+}
+
+// dayStart returns 05:00 on the simulated day: the day the provider returned
+// for -location, else date, else today.
+func (wf *weatherFlags) dayStart(w calc.Weather, date string) (time.Time, error) {
+	if err := wf.checkDate(date); err != nil {
+		return time.Time{}, err
+	}
+	if date != "" {
+		day, err := time.ParseInLocation(time.DateOnly, date, time.Local)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid -date: %w", err)
+		}
+		return export.DayStart(day), nil
+	}
+	if !w.Start.IsZero() {
+		return w.Start, nil
+	}
+	return export.DayStart(time.Now()), nil
+}
+
+// reportCache prints cache fallbacks and failures to stderr, stdout may carry
+// an export.
+func reportCache(err error) {
+	fmt.Fprintln(os.Stderr, err)
+}
+
+// interpolationFlags choose how hourly weather becomes one value per minute.
+type interpolationFlags struct {
+	method    string
+	smoothing int
+}
+
+func (f *interpolationFlags) register(fs *flag.FlagSet) {
+	d := interop.DefaultOptions
+	fs.StringVar(&f.method, "interp", string(d.Method), "interpolation of hourly weather: linear, monotone, spline or catmull-rom")
+	fs.IntVar(&f.smoothing, "smooth", d.Smoothing, "Gaussian smoothing window either side in minutes after interpolating, 0 keeps the peaks; 30 with catmull-rom reproduces results from earlier versions")
+}
+
+// apply sets interop.Default, which every weather source uses.
+func (f *interpolationFlags) apply() error {
+	method, err := interop.ParseMethod(f.method)
+	if err != nil {
+		return err
+	}
+	opts := interop.Options{Method: method, Smoothing: f.smoothing}
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	interop.Default = opts
+	return nil
+}
+
+// qualityFlags configure the checks of hourly weather before it is simulated.
+type qualityFlags struct {
+	fill   string
+	unit   string
+	maxGap float64
+}
+
+func (f *qualityFlags) register(fs *flag.FlagSet) {
+	d := quality.DefaultConfig()
+	fs.StringVar(&f.fill, "fill", string(d.Fill), "how gaps in hourly weather are filled: interpolate, linear, previous or none")
+	fs.Float64Var(&f.maxGap, "max-gap", d.MaxGap, "longest gap in minutes that is filled, longer gaps refuse the data")
+	fs.StringVar(&f.unit, "unit", string(d.Unit), "unit of the temperatures in weather files: auto, C, F or K")
+}
+
+// apply sets quality.Default and prints what the checks changed to stderr.
+func (f *qualityFlags) apply() error {
+	fill, err := quality.ParseFill(f.fill)
+	if err != nil {
+		return err
+	}
+	unit, err := quality.ParseUnit(f.unit)
+	if err != nil {
+		return err
+	}
+	if f.maxGap < 0 {
+		return fmt.Errorf("-max-gap must not be negative, got %g", f.maxGap)
+	}
+	quality.Default.Fill = fill
+	quality.Default.Unit = unit
+	quality.Default.MaxGap = f.maxGap
+	weatherdata.ReportQuality = func(source string, r quality.Report) {
+		fmt.Fprintf(os.Stderr, "weather %s: ", source)
+		r.WriteText(os.Stderr)
+	}
+	return nil
+}
+
+// newProvider picks the named weather provider. Without a name OpenWeatherMap
+// is used when its token file can be read, Open-Meteo otherwise.
+func newProvider(name, tokenPath string) (weatherdata.Provider, error) {
+	token, tokenErr := "", error(nil)
+	if tokenPath != "" {
+		token, tokenErr = freader.ReadToken(tokenPath)
+	}
+	switch {
+	case name == "" && token != "":
+		name = weatherdata.ProviderOpenWeatherMap
+	case name == "":
+		name = weatherdata.ProviderOpenMeteo
+	case name == weatherdata.ProviderOpenWeatherMap && tokenErr != nil:
+		return nil, tokenErr
+	}
+	return weatherdata.NewProvider(name, token)
+}
+
+func writeFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"heat-transfer/climate"
+	"heat-transfer/scenario"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	register("climate", "summarise weather with degree-days, monthly statistics and diurnal ranges", runClimate)
+}
+
+func runClimate(args []string) error {
+	fs := flag.NewFlagSet("climate", flag.ContinueOnError)
+	var wf weatherFlags
+	wf.register(fs)
+	epwPath := fs.String("epw", "", "EnergyPlus weather file, e.g. a TMY year, instead of -weather or -location")
+	csvPath := fs.String("hourly", "", "CSV of historical hourly observations, rows of \"timestamp,temp\"")
+	bases := fs.String("bases", "18,24", "comma separated base temperatures for degree-days, °C")
+	basePath := fs.String("base", "", "JSON file with a scenario to estimate AC energy for from degree-days")
+	jsonPath := fs.String("json", "", "write the summary as JSON to this file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var baseTemps []float64
+	for _, b := range strings.Split(*bases, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(b), 64)
+		if err != nil {
+			return fmt.Errorf("invalid base temperature %q", b)
+		}
+		baseTemps = append(baseTemps, v)
+	}
+
+	// a single simulated day from -weather or -location
+	var outside *[840]float64
+	var rec climate.Record
+	var err error
+	switch {
+	case *epwPath != "":
+		rec, err = climate.LoadEPW(*epwPath)
+	case *csvPath != "":
+		rec, err = climate.LoadHourlyCSV(*csvPath)
+	default:
+		var temps [840]float64
+		if temps, err = wf.load(); err == nil {
+			outside = &temps
+			rec = climate.FromDay(time.Now(), temps)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	summary := climate.Summarize(rec, baseTemps)
+	if loc := rec.Location; loc.City != "" {
+		fmt.Printf("%s, %s (%s)\n", loc.City, loc.Country, loc.Source)
+	}
+	if err := summary.WriteText(os.Stdout); err != nil {
+		return err
+	}
+
+	if *basePath != "" {
+		if err := printDegreeDayEnergy(*basePath, rec, outside); err != nil {
+			return err
+		}
+	}
+
+	if *jsonPath != "" {
+		return writeFile(*jsonPath, func(w io.Writer) error {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(summary)
+		})
+	}
+	return nil
+}
+
+// printDegreeDayEnergy compares the degree-day estimate of the AC energy with
+// the simulation when the source is a single day, or lists it per month for
+// longer records.
+func printDegreeDayEnergy(basePath string, rec climate.Record, outside *[840]float64) error {
+	list, err := scenario.LoadScenarios(basePath)
+	if err != nil {
+		return err
+	}
+	s := list[0]
+	if s.AC == nil || !s.AC.Enabled {
+		return fmt.Errorf("scenario %q has no AC", s.Name)
+	}
+
+	if outside != nil {
+		r, err := scenario.Run(s, *outside)
+		if err != nil {
+			return err
+		}
+		estimate := climate.ScheduledCoolingEnergy(r.UA(), *outside, s.AC.SetTemp, s.AC.OnTime, s.AC.OffTime)
+		fmt.Printf("\n%s, UA %.1f W/K: degree-hour estimate %.2f kWh/day, simulated %.2f kWh/day\n",
+			s.Name, r.UA(), estimate, r.CoolingKWh())
+		return nil
+	}
+
+	// the UA does not depend on the weather, any day will do
+	r, err := scenario.Run(s, [840]float64{})
+	if err != nil {
+		return err
+	}
+	monthly := climate.Summarize(rec, []float64{s.AC.SetTemp})
+	fmt.Printf("\n%s, UA %.1f W/K, AC running all day at %g °C:\n", s.Name, r.UA(), s.AC.SetTemp)
+	for _, m := range monthly.Months {
+		cdd := m.DegreeDays[0].Cooling
+		fmt.Printf("%-5s %8.1f K·day %8.1f kWh %6.2f kWh/day\n",
+			m.Month.String()[:3], cdd, climate.CoolingEnergy(r.UA(), cdd), climate.CoolingEnergy(r.UA(), cdd)/float64(m.Days))
+	}
+	cdd := monthly.Annual.DegreeDays[0].Cooling
+	fmt.Printf("%-5s %8.1f K·day %8.1f kWh\n", "All", cdd, climate.CoolingEnergy(r.UA(), cdd))
+	return nil
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"heat-transfer/chartings"
+	"heat-transfer/export"
+	"heat-transfer/finance"
+	"heat-transfer/scenario"
+	"io"
+	"os"
+)
+
+func init() {
+	register("compare", "run several scenarios against the same weather and compare them", runCompare)
+}
+
+func runCompare(args []string) error {
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+	var wf weatherFlags
+	wf.register(fs)
+	scenariosPath := fs.String("scenarios", "", "JSON file with an array of scenarios, the first is the baseline")
+	chartPath := fs.String("chart", "", "write an overlaid temperature chart (PNG) to this file")
+	jsonPath := fs.String("json", "", "write the full comparison as JSON to this file")
+	xlsxPath := fs.String("xlsx", "", "write an Excel workbook with inputs, time series, costs and charts to this file")
+	date := fs.String("date", "", dateUsage)
+	assumptions := finance.DefaultAssumptions()
+	fs.IntVar(&assumptions.Years, "years", assumptions.Years, "life-cycle analysis period in years")
+	fs.Float64Var(&assumptions.DiscountRate, "discount", assumptions.DiscountRate, "discount rate per year")
+	fs.Float64Var(&assumptions.EnergyEscalation, "escalation", assumptions.EnergyEscalation, "electricity price escalation per year")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *scenariosPath == "" {
+		return errors.New("-scenarios is required")
+	}
+	if err := wf.checkDate(*date); err != nil {
+		return err
+	}
+	scenarios, err := scenario.LoadScenarios(*scenariosPath)
+	if err != nil {
+		return err
+	}
+
+	weather, err := wf.loadWeather()
+	if err != nil {
+		return err
+	}
+	start, err := wf.dayStart(weather, *date)
+	if err != nil {
+		return err
+	}
+
+	comparison, err := scenario.Compare(scenarios, weather.Temp, assumptions)
+	if err != nil {
+		return err
+	}
+
+	if err := comparison.WriteTable(os.Stdout); err != nil {
+		return err
+	}
+
+	if *chartPath != "" {
+		buf, err := comparisonChart(comparison)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(*chartPath, buf, 0o644); err != nil {
+			return err
+		}
+	}
+
+	if *xlsxPath != "" {
+		wb, err := export.ComparisonWorkbook(comparison, start)
+		if err != nil {
+			return err
+		}
+		if err := writeFile(*xlsxPath, wb.Write); err != nil {
+			return err
+		}
+	}
+
+	if *jsonPath != "" {
+		err := writeFile(*jsonPath, func(w io.Writer) error {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(comparison)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func comparisonChart(c scenario.Comparison) ([]byte, error) {
+	base := c.Results[0]
+	series := make([]chartings.Series, 0, len(c.Results))
+	for _, r := range c.Results {
+		series = append(series, chartings.Series{
+			Name:   r.Scenario.Name,
+			Points: chartings.XYs(r.TimeMinutes, r.InsideTemps),
+		})
+	}
+
+	buf, err := chartings.PlotComparison(chartings.XYs(base.TimeMinutes, base.OutsideTemps), series)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"heat-transfer/climate"
+	"heat-transfer/scenario"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	register("design", "derive cooling design days from an EPW/TMY file or hourly history", runDesign)
+}
+
+func runDesign(args []string) error {
+	fs := flag.NewFlagSet("design", flag.ContinueOnError)
+	epwPath := fs.String("epw", "", "EnergyPlus weather file, e.g. a TMY year")
+	csvPath := fs.String("hourly", "", "CSV of historical hourly observations, rows of \"timestamp,temp\"")
+	percents := fs.String("percent", "0.4,1,2", "comma separated annual percentages of hours the design temperature is exceeded")
+	outPath := fs.String("out", "", "write the design day of the first percentage as an hourly weather CSV, usable with -weather")
+	basePath := fs.String("base", "", "JSON file with a scenario to simulate on the design day of the first percentage")
+	jsonPath := fs.String("json", "", "write the design conditions as JSON to this file")
+	var interp interpolationFlags
+	interp.register(fs)
+	var qf qualityFlags
+	qf.register(fs)
+	recordDay := fs.String("day", "", "use this day of the record (MM-DD), e.g. a typical TMY day, for -out and -base instead of the design day")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := interp.apply(); err != nil {
+		return err
+	}
+	if err := qf.apply(); err != nil {
+		return err
+	}
+
+	var rec climate.Record
+	var err error
+	switch {
+	case *epwPath != "":
+		rec, err = climate.LoadEPW(*epwPath)
+	case *csvPath != "":
+		rec, err = climate.LoadHourlyCSV(*csvPath)
+	default:
+		return errors.New("either -epw or -hourly is required")
+	}
+	if err != nil {
+		return err
+	}
+
+	var conditions []climate.DesignConditions
+	for _, p := range strings.Split(*percents, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return fmt.Errorf("invalid percentage %q", p)
+		}
+		dc, err := climate.Design(rec, v)
+		if err != nil {
+			return err
+		}
+		conditions = append(conditions, dc)
+	}
+
+	if loc := rec.Location; loc.City != "" {
+		fmt.Printf("%s, %s (%s)\n", loc.City, loc.Country, loc.Source)
+	}
+	fmt.Printf("%d hours, hottest month %s with a mean daily range of %.1f K\n",
+		conditions[0].Hours, conditions[0].HottestMonth, conditions[0].HottestMonthRange)
+	for _, dc := range conditions {
+		fmt.Printf("%4g%%  dry bulb %5.1f °C  coincident daily range %4.1f K  (%d days)\n",
+			dc.Percent, dc.DryBulb, dc.DailyRange, dc.DesignDays)
+	}
+
+	hourly := conditions[0].Day().Daytime()
+	label := fmt.Sprintf("the %g%% design day", conditions[0].Percent)
+	if *recordDay != "" {
+		date, err := time.Parse("01-02", *recordDay)
+		if err != nil {
+			return fmt.Errorf("invalid -day: %w", err)
+		}
+		if hourly, err = rec.Daytime(date.Month(), date.Day()); err != nil {
+			return err
+		}
+		label = date.Format("January 2")
+	}
+
+	if *outPath != "" {
+		err := writeFile(*outPath, func(w io.Writer) error {
+			cw := csv.NewWriter(w)
+			cw.Write([]string{"time", "temp_c"})
+			for i, t := range hourly {
+				cw.Write([]string{fmt.Sprintf("%02d:00", i+5), strconv.FormatFloat(t, 'f', 2, 64)})
+			}
+			cw.Flush()
+			return cw.Error()
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if *basePath != "" {
+		list, err := scenario.LoadScenarios(*basePath)
+		if err != nil {
+			return err
+		}
+		outside, report, err := climate.DaytimeProfile(hourly)
+		if len(report.Issues) > 0 {
+			fmt.Fprintf(os.Stderr, "weather %s: ", label)
+			report.WriteText(os.Stderr)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", label, err)
+		}
+		r, err := scenario.Run(list[0], outside)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s on %s: peak inside %.1f °C, AC %d min/day, %.2f THB/month\n",
+			r.Scenario.Name, label, r.PeakInsideTemp, r.ACMinutes, r.MonthlyACCost)
+	}
+
+	if *jsonPath != "" {
+		err := writeFile(*jsonPath, func(w io.Writer) error {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(conditions)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
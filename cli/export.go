@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"heat-transfer/export"
+	"heat-transfer/scenario"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	register("export", "simulate a scenario and export the time series", runExport)
+}
+
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	var wf weatherFlags
+	wf.register(fs)
+	basePath := fs.String("base", "", "JSON file with the scenario")
+	format := fs.String("format", "", "csv, json or xlsx, defaults to the -out extension or csv")
+	outPath := fs.String("out", "", "output file, stdout when empty")
+	date := fs.String("date", "", dateUsage)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *basePath == "" {
+		return errors.New("-base is required")
+	}
+	if err := wf.checkDate(*date); err != nil {
+		return err
+	}
+	list, err := scenario.LoadScenarios(*basePath)
+	if err != nil {
+		return err
+	}
+
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*outPath)), ".")
+		if *format == "" {
+			*format = "csv"
+		}
+	}
+
+	if *format != "csv" && *format != "json" && *format != "xlsx" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	if *format == "xlsx" && *outPath == "" {
+		return errors.New("xlsx output needs -out")
+	}
+
+	weather, err := wf.loadWeather()
+	if err != nil {
+		return err
+	}
+
+	start, err := wf.dayStart(weather, *date)
+	if err != nil {
+		return err
+	}
+
+	r, err := scenario.RunWeather(list[0], weather)
+	if err != nil {
+		return err
+	}
+
+	write := func(w io.Writer) error {
+		switch *format {
+		case "xlsx":
+			wb, err := export.ScenarioWorkbook(r, start)
+			if err != nil {
+				return err
+			}
+			return wb.Write(w)
+		case "json":
+			return export.FromResult(r, start).WriteJSON(w)
+		default:
+			return export.FromResult(r, start).WriteCSV(w)
+		}
+	}
+
+	if *outPath == "" {
+		return write(os.Stdout)
+	}
+	return writeFile(*outPath, write)
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"heat-transfer/finance"
+	"io"
+)
+
+func init() {
+	register("lifecycle", "compare the life-cycle cost of an upgrade with a baseline: NPV, payback and IRR", runLifecycle)
+}
+
+// financeFlags registers the cost flags of one option, e.g. -upgrade-cost.
+func financeFlags(fs *flag.FlagSet, prefix, name string) *finance.Scenario {
+	s := &finance.Scenario{Name: name}
+	fs.StringVar(&s.Name, prefix+"-name", name, "name of the "+prefix)
+	fs.Float64Var(&s.InitialCost, prefix+"-cost", 0, "up-front material and installation cost of the "+prefix+" (THB)")
+	fs.Float64Var(&s.AnnualEnergyCost, prefix+"-energy", 0, "first-year electricity cost of the "+prefix+" (THB)")
+	fs.Float64Var(&s.AnnualMaintenance, prefix+"-maintenance", 0, "first-year maintenance cost of the "+prefix+" (THB)")
+	return s
+}
+
+func runLifecycle(args []string) error {
+	fs := flag.NewFlagSet("lifecycle", flag.ContinueOnError)
+	baseline := financeFlags(fs, "baseline", "Baseline")
+	upgrade := financeFlags(fs, "upgrade", "Upgrade")
+	assumptions := finance.DefaultAssumptions()
+	fs.IntVar(&assumptions.Years, "years", assumptions.Years, "analysis period in years")
+	fs.Float64Var(&assumptions.DiscountRate, "discount", assumptions.DiscountRate, "discount rate per year")
+	fs.Float64Var(&assumptions.EnergyEscalation, "escalation", assumptions.EnergyEscalation, "electricity price escalation per year")
+	fs.Float64Var(&assumptions.MaintenanceEscalation, "maintenance-escalation", assumptions.MaintenanceEscalation, "maintenance cost escalation per year")
+	csvPath := fs.String("csv", "", "write the year by year cash flows as CSV to this file")
+	jsonPath := fs.String("json", "", "write the full report as JSON to this file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	r, err := finance.Analyze(*baseline, *upgrade, assumptions)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(r)
+	fmt.Printf("\n%4s %14s %14s %14s %14s\n", "Year", "Baseline", "Upgrade", "Cash flow", "Discounted")
+	for _, row := range r.Rows {
+		fmt.Printf("%4d %14.2f %14.2f %14.2f %14.2f\n", row.Year, row.BaselineCost, row.UpgradeCost, row.CashFlow, row.DiscountedCumulative)
+	}
+
+	if *csvPath != "" {
+		if err := writeFile(*csvPath, r.WriteCSV); err != nil {
+			return err
+		}
+	}
+	if *jsonPath != "" {
+		return writeFile(*jsonPath, func(w io.Writer) error {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(r)
+		})
+	}
+	return nil
+}
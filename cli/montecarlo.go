@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"heat-transfer/scenario"
+	"heat-transfer/uncertainty"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	register("montecarlo", "sample uncertain inputs and report percentile bands", runMonteCarlo)
+}
+
+func runMonteCarlo(args []string) error {
+	fs := flag.NewFlagSet("montecarlo", flag.ContinueOnError)
+	var wf weatherFlags
+	wf.register(fs)
+	basePath := fs.String("base", "", "JSON file with the base scenario")
+	inputsPath := fs.String("inputs", "", "JSON file with an array of uncertain inputs, defaults to conductivity, infiltration and tariff")
+	params := uncertainty.MonteCarloParams{}
+	fs.IntVar(&params.Samples, "n", 500, "number of samples")
+	fs.Uint64Var(&params.Seed, "seed", 1, "random seed")
+	fs.IntVar(&params.Workers, "workers", 0, "number of parallel workers, 0 uses every CPU")
+	percentiles := fs.String("percentiles", "10,50,90", "comma separated percentiles to report")
+	bandsPath := fs.String("bands", "", "write the inside temperature percentile bands as CSV to this file")
+	samplesPath := fs.String("samples", "", "write every sample's inputs and outputs as CSV to this file")
+	jsonPath := fs.String("json", "", "write the summary as JSON to this file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *basePath == "" {
+		return errors.New("-base is required")
+	}
+	list, err := scenario.LoadScenarios(*basePath)
+	if err != nil {
+		return err
+	}
+
+	inputs := uncertainty.DefaultInputs()
+	if *inputsPath != "" {
+		buf, err := os.ReadFile(*inputsPath)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(buf, &inputs); err != nil {
+			return fmt.Errorf("%s: %w", *inputsPath, err)
+		}
+	}
+
+	for _, p := range strings.Split(*percentiles, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil || v < 0 || v > 100 {
+			return fmt.Errorf("invalid percentile %q", p)
+		}
+		params.Percentiles = append(params.Percentiles, v)
+	}
+
+	outside, err := wf.load()
+	if err != nil {
+		return err
+	}
+
+	res, err := uncertainty.RunMonteCarlo(context.Background(), list[0], outside, inputs, params)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d samples (seed %d, %d failed)\n", len(res.Outputs), res.Seed, res.Failed)
+	fmt.Printf("peak inside temperature (°C): %s\n", res.PeakInsideTemp)
+	fmt.Printf("AC runtime (min/day):         %s\n", res.ACMinutes)
+	fmt.Printf("monthly AC cost (THB):        %s\n", res.MonthlyCost)
+
+	if *bandsPath != "" {
+		if err := writeFile(*bandsPath, res.WriteBandsCSV); err != nil {
+			return err
+		}
+	}
+	if *samplesPath != "" {
+		if err := writeFile(*samplesPath, res.WriteSamplesCSV); err != nil {
+			return err
+		}
+	}
+	if *jsonPath != "" {
+		err := writeFile(*jsonPath, func(w io.Writer) error {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(res)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"heat-transfer/report"
+	"heat-transfer/scenario"
+	"io"
+	"time"
+)
+
+func init() {
+	register("report", "simulate a scenario and write a PDF engineering report", runReport)
+}
+
+func runReport(args []string) error {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	var wf weatherFlags
+	wf.register(fs)
+	basePath := fs.String("base", "", "JSON file with the scenario")
+	outPath := fs.String("out", "report.pdf", "output PDF file")
+	var info report.ProjectInfo
+	fs.StringVar(&info.Title, "title", "", "report title")
+	fs.StringVar(&info.Project, "project", "", "project name")
+	fs.StringVar(&info.Client, "client", "", "client name")
+	fs.StringVar(&info.Location, "site", "", "site address printed on the report")
+	fs.StringVar(&info.Engineer, "engineer", "", "engineer responsible")
+	fs.StringVar(&info.Reference, "ref", "", "document reference")
+	date := fs.String("date", time.Now().Format(time.DateOnly), "report date (YYYY-MM-DD)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *basePath == "" {
+		return errors.New("-base is required")
+	}
+	list, err := scenario.LoadScenarios(*basePath)
+	if err != nil {
+		return err
+	}
+
+	info.Date, err = time.ParseInLocation(time.DateOnly, *date, time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid -date: %w", err)
+	}
+
+	outside, err := wf.load()
+	if err != nil {
+		return err
+	}
+
+	r, err := scenario.Run(list[0], outside)
+	if err != nil {
+		return err
+	}
+
+	return writeFile(*outPath, func(w io.Writer) error {
+		return report.Write(w, info, r)
+	})
+}
@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"heat-transfer/chartings"
+	"heat-transfer/scenario"
+	"heat-transfer/sensitivity"
+	"heat-transfer/uncertainty"
+	"io"
+	"os"
+	"slices"
+)
+
+func init() {
+	register("sensitivity", "rank inputs by influence with tornado and Sobol analysis", runSensitivity)
+}
+
+var outputUnits = map[string]string{
+	uncertainty.OutputPeakInsideTemp: "Peak inside temperature (°C)",
+	uncertainty.OutputACMinutes:      "AC runtime (minutes/day)",
+	uncertainty.OutputMonthlyCost:    "Monthly AC cost (THB)",
+}
+
+func runSensitivity(args []string) error {
+	fs := flag.NewFlagSet("sensitivity", flag.ContinueOnError)
+	var wf weatherFlags
+	wf.register(fs)
+	basePath := fs.String("base", "", "JSON file with the base scenario")
+	inputsPath := fs.String("inputs", "", "JSON file with an array of uncertain inputs, defaults to conductivity, infiltration and tariff")
+	method := fs.String("method", "both", "tornado, sobol or both")
+	low := fs.Float64("low", 10, "percentile used as the low tornado setting")
+	high := fs.Float64("high", 90, "percentile used as the high tornado setting")
+	samples := fs.Int("n", 256, "Sobol base sample count, runs = n * (inputs + 2)")
+	seed := fs.Uint64("seed", 1, "random seed")
+	workers := fs.Int("workers", 0, "number of parallel workers, 0 uses every CPU")
+	tornadoCSV := fs.String("tornado-csv", "", "write tornado results as CSV to this file")
+	sobolCSV := fs.String("sobol-csv", "", "write Sobol indices as CSV to this file")
+	jsonPath := fs.String("json", "", "write every result as JSON to this file")
+	chartPath := fs.String("chart", "", "write a tornado chart (PNG) to this file")
+	chartOutput := fs.String("chart-output", uncertainty.OutputMonthlyCost, "output shown in the tornado chart")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *method != "tornado" && *method != "sobol" && *method != "both" {
+		return fmt.Errorf("unknown method %q", *method)
+	}
+	if !slices.Contains(uncertainty.OutputNames(), *chartOutput) {
+		return fmt.Errorf("unknown output %q, expected one of %v", *chartOutput, uncertainty.OutputNames())
+	}
+	for _, pct := range []float64{*low, *high} {
+		if pct < 0 || pct > 100 {
+			return fmt.Errorf("invalid percentile %g, expected 0 to 100", pct)
+		}
+	}
+	if *basePath == "" {
+		return errors.New("-base is required")
+	}
+	list, err := scenario.LoadScenarios(*basePath)
+	if err != nil {
+		return err
+	}
+
+	inputs := uncertainty.DefaultInputs()
+	if *inputsPath != "" {
+		buf, err := os.ReadFile(*inputsPath)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(buf, &inputs); err != nil {
+			return fmt.Errorf("%s: %w", *inputsPath, err)
+		}
+	}
+
+	outside, err := wf.load()
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		Tornado *sensitivity.Tornado `json:"tornado,omitempty"`
+		Sobol   *sensitivity.Sobol   `json:"sobol,omitempty"`
+	}
+
+	if *method != "sobol" {
+		t, err := sensitivity.RunTornado(list[0], outside, inputs, *low, *high)
+		if err != nil {
+			return err
+		}
+		result.Tornado = &t
+
+		for _, name := range uncertainty.OutputNames() {
+			fmt.Printf("tornado, %s (baseline %.2f)\n", outputUnits[name], t.Baseline[name])
+			for _, bar := range t.Bars[name] {
+				fmt.Printf("  %-16s %10.2f .. %10.2f  swing %.2f\n", bar.Input, bar.Low, bar.High, bar.Swing)
+			}
+		}
+	}
+
+	if *method != "tornado" {
+		s, err := sensitivity.RunSobol(context.Background(), list[0], outside, inputs, *samples, *seed, *workers)
+		if err != nil {
+			return err
+		}
+		result.Sobol = &s
+
+		for _, name := range uncertainty.OutputNames() {
+			fmt.Printf("sobol, %s (%d runs)\n", outputUnits[name], s.Evaluations)
+			for _, idx := range s.Indices[name] {
+				fmt.Printf("  %-16s first %6.3f  total %6.3f\n", idx.Input, idx.FirstOrder, idx.Total)
+			}
+		}
+	}
+
+	if *tornadoCSV != "" && result.Tornado != nil {
+		if err := writeFile(*tornadoCSV, result.Tornado.WriteCSV); err != nil {
+			return err
+		}
+	}
+	if *sobolCSV != "" && result.Sobol != nil {
+		if err := writeFile(*sobolCSV, result.Sobol.WriteCSV); err != nil {
+			return err
+		}
+	}
+	if *jsonPath != "" {
+		err := writeFile(*jsonPath, func(w io.Writer) error {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(result)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if *chartPath != "" {
+		if result.Tornado == nil {
+			return errors.New("-chart needs the tornado method")
+		}
+		var bars []chartings.TornadoBar
+		for _, bar := range result.Tornado.Bars[*chartOutput] {
+			bars = append(bars, chartings.TornadoBar{Label: bar.Input, Low: bar.Low, High: bar.High})
+		}
+		buf, err := chartings.PlotTornado("Sensitivity", outputUnits[*chartOutput], result.Tornado.Baseline[*chartOutput], bars)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(*chartPath, buf.Bytes(), 0o644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"heat-transfer/server"
+	weatherdata "heat-transfer/weatherData"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+)
+
+func init() {
+	register("serve", "run the JSON HTTP API", runServe)
+}
+
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", "localhost:8080", "listen address")
+	weatherPath := fs.String("weather", "", "CSV file of hourly outside temperatures used when a request has none, keeps the server offline")
+	location := fs.String("location", "", "city whose forecast is used when a request has no weather")
+	tokenPath := fs.String("token", "", "OpenWeatherMap API token file, enables weather.location in requests")
+	provider := fs.String("provider", "", "weather provider for locations, openweathermap or openmeteo; defaults to openweathermap with -token and offline without")
+	cacheDir := fs.String("cache-dir", weatherdata.DefaultCacheDir(), "directory for cached API responses, empty disables the cache")
+	var interp interpolationFlags
+	interp.register(fs)
+	var qf qualityFlags
+	qf.register(fs)
+	var cfg server.Config
+	fs.IntVar(&cfg.Jobs.Workers, "workers", 0, "background jobs running at once, 0 uses one per CPU")
+	fs.IntVar(&cfg.Jobs.MaxQueued, "max-queued", 100, "background jobs allowed to wait for a worker")
+	fs.DurationVar(&cfg.Jobs.TTL, "job-ttl", time.Hour, "how long finished job results are kept")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	weatherdata.DefaultCache = weatherdata.NewCache(*cacheDir)
+	weatherdata.ReportCache = reportCache
+	if err := interp.apply(); err != nil {
+		return err
+	}
+	if err := qf.apply(); err != nil {
+		return err
+	}
+
+	if *tokenPath != "" || *provider != "" {
+		p, err := newProvider(*provider, *tokenPath)
+		if err != nil {
+			return err
+		}
+		cfg.Provider = p
+	}
+
+	switch {
+	case *weatherPath != "":
+		// read once so a broken file fails at startup
+		outside, err := weatherdata.GetTemperatureFromFile(*weatherPath)
+		if err != nil {
+			return err
+		}
+		cfg.DefaultWeather = func() ([840]float64, error) { return outside, nil }
+	case *location != "":
+		if cfg.Provider == nil {
+			return errors.New("-location needs -token or -provider")
+		}
+		cfg.DefaultWeather = func() ([840]float64, error) {
+			w, err := cfg.Provider.Forecast(*location)
+			return w.Temp, err
+		}
+	}
+
+	api := server.New(cfg)
+	defer api.Close()
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           api,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	errc := make(chan error, 1)
+	go func() {
+		fmt.Fprintf(os.Stderr, "listening on http://%s/api/v1\n", *addr)
+		errc <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return srv.Shutdown(shutdown)
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"heat-transfer/batch"
+	"heat-transfer/export"
+	"heat-transfer/scenario"
+	"io"
+	"os"
+	"time"
+)
+
+func init() {
+	register("sweep", "run a parameter grid in parallel and write a results table", runSweep)
+}
+
+func runSweep(args []string) error {
+	fs := flag.NewFlagSet("sweep", flag.ContinueOnError)
+	var wf weatherFlags
+	wf.register(fs)
+	basePath := fs.String("base", "", "JSON file with the base scenario")
+	gridPath := fs.String("grid", "", "JSON file with the parameter grid")
+	thicknesses := fs.String("thickness", "", "thicknesses in m, \"from:to:step\" or a comma separated list")
+	materials := fs.String("materials", "", "comma separated material keys")
+	setTemps := fs.String("setpoint", "", "AC set temperatures in °C, range or list")
+	powers := fs.String("power", "", "AC cooling power in W, range or list")
+	schedules := fs.String("schedule", "", "comma separated AC windows, e.g. 08:00-17:00,10:00-19:00")
+	workers := fs.Int("workers", 0, "number of parallel workers, 0 uses every CPU")
+	outPath := fs.String("out", "", "write the results as CSV to this file instead of stdout")
+	jsonOut := fs.Bool("json", false, "write JSON instead of CSV")
+	xlsxPath := fs.String("xlsx", "", "also write the results as an Excel workbook to this file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *basePath == "" {
+		return errors.New("-base is required")
+	}
+	list, err := scenario.LoadScenarios(*basePath)
+	if err != nil {
+		return err
+	}
+	base := list[0]
+
+	var grid batch.Grid
+	if *gridPath != "" {
+		buf, err := os.ReadFile(*gridPath)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(buf, &grid); err != nil {
+			return fmt.Errorf("%s: %w", *gridPath, err)
+		}
+	}
+
+	// flags override the grid file
+	if grid.Thicknesses, err = override(grid.Thicknesses, *thicknesses); err != nil {
+		return err
+	}
+	if grid.SetTemps, err = override(grid.SetTemps, *setTemps); err != nil {
+		return err
+	}
+	if grid.ACPowers, err = override(grid.ACPowers, *powers); err != nil {
+		return err
+	}
+	if *materials != "" {
+		if grid.Materials = batch.ParseMaterials(*materials); len(grid.Materials) == 0 {
+			return fmt.Errorf("-materials %q lists no material keys", *materials)
+		}
+	}
+	if *schedules != "" {
+		if grid.Schedules, err = batch.ParseSchedules(*schedules); err != nil {
+			return err
+		}
+	}
+
+	outside, err := wf.load()
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	rows, err := batch.Run(context.Background(), base, grid, outside, *workers, nil)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d runs in %s\n", len(rows), time.Since(start).Round(time.Millisecond))
+
+	if *xlsxPath != "" {
+		if err := writeFile(*xlsxPath, export.BatchWorkbook(rows).Write); err != nil {
+			return err
+		}
+	}
+
+	write := func(w io.Writer) error {
+		if *jsonOut {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(rows)
+		}
+		return batch.WriteCSV(w, rows)
+	}
+	if *outPath == "" {
+		return write(os.Stdout)
+	}
+	return writeFile(*outPath, write)
+}
+
+func override(values []float64, flagValue string) ([]float64, error) {
+	if flagValue == "" {
+		return values, nil
+	}
+	return batch.ParseRange(flagValue)
+}
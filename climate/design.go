@@ -0,0 +1,194 @@
+package climate
+
+import (
+	"errors"
+	"fmt"
+	"heat-transfer/interop"
+	"heat-transfer/quality"
+	"math"
+	"sort"
+	"time"
+)
+
+var nan = math.NaN()
+
+// DesignConditions are cooling design values in the manner of the ASHRAE
+// climatic design tables.
+type DesignConditions struct {
+	Percent    float64 `json:"percent"`     // share of hours the dry bulb is exceeded, e.g. 0.4
+	DryBulb    float64 `json:"dry_bulb"`    // °C
+	DailyRange float64 `json:"daily_range"` // mean range of the days reaching DryBulb, K
+	DesignDays int     `json:"design_days"` // days reaching DryBulb
+
+	HottestMonth      time.Month `json:"hottest_month"`
+	HottestMonthRange float64    `json:"hottest_month_range"` // mean daily range, K
+
+	Hours int `json:"hours"` // observations used
+}
+
+// a day needs most of its hours for a meaningful range
+const minHoursPerDay = 20
+
+type daySummary struct {
+	month    time.Month
+	max, min float64
+	hours    int
+}
+
+// Design derives the cooling design conditions exceeded for percent of the
+// hours of the record, usually 0.4, 1 or 2.
+func Design(rec Record, percent float64) (DesignConditions, error) {
+	if percent <= 0 || percent >= 50 {
+		return DesignConditions{}, fmt.Errorf("design percentage must lie between 0 and 50, got %g", percent)
+	}
+	if len(rec.Hours) < 24*28 {
+		return DesignConditions{}, errors.New("design conditions need at least four weeks of hourly data")
+	}
+
+	temps := make([]float64, len(rec.Hours))
+	for i, h := range rec.Hours {
+		temps[i] = h.Temp
+	}
+	sort.Float64s(temps)
+
+	dc := DesignConditions{
+		Percent: percent,
+		DryBulb: percentile(temps, 100-percent),
+		Hours:   len(temps),
+	}
+
+	days := map[string]*daySummary{}
+	monthSum := map[time.Month]float64{}
+	monthCount := map[time.Month]int{}
+	for _, h := range rec.Hours {
+		key := h.Time.Format(time.DateOnly)
+		d, ok := days[key]
+		if !ok {
+			d = &daySummary{month: h.Time.Month(), max: math.Inf(-1), min: math.Inf(1)}
+			days[key] = d
+		}
+		d.max = math.Max(d.max, h.Temp)
+		d.min = math.Min(d.min, h.Temp)
+		d.hours++
+
+		monthSum[h.Time.Month()] += h.Temp
+		monthCount[h.Time.Month()]++
+	}
+
+	hottest := math.Inf(-1)
+	for m, n := range monthCount {
+		if mean := monthSum[m] / float64(n); mean > hottest {
+			hottest, dc.HottestMonth = mean, m
+		}
+	}
+
+	designSum, monthRangeSum, monthDays := 0.0, 0.0, 0
+	for _, d := range days {
+		if d.hours < minHoursPerDay {
+			continue
+		}
+		if d.max >= dc.DryBulb {
+			designSum += d.max - d.min
+			dc.DesignDays++
+		}
+		if d.month == dc.HottestMonth {
+			monthRangeSum += d.max - d.min
+			monthDays++
+		}
+	}
+	if monthDays > 0 {
+		dc.HottestMonthRange = monthRangeSum / float64(monthDays)
+	}
+
+	// too few complete days reach the design temperature in short records
+	dc.DailyRange = dc.HottestMonthRange
+	if dc.DesignDays > 0 {
+		dc.DailyRange = designSum / float64(dc.DesignDays)
+	}
+
+	return dc, nil
+}
+
+// percentile of sorted values (0-100) with linear interpolation.
+func percentile(sorted []float64, p float64) float64 {
+	pos := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	if lo == hi {
+		return sorted[lo]
+	}
+	return sorted[lo] + (pos-float64(lo))*(sorted[hi]-sorted[lo])
+}
+
+// ashraeRangeFractions is the fraction of the daily range below the peak for
+// clock hours 1 to 24, ASHRAE Fundamentals, chapter 14.
+var ashraeRangeFractions = [24]float64{
+	0.88, 0.92, 0.95, 0.98, 1.00, 0.98, 0.91, 0.74, 0.55, 0.38, 0.23, 0.13,
+	0.05, 0.00, 0.00, 0.06, 0.14, 0.24, 0.39, 0.50, 0.59, 0.68, 0.75, 0.82,
+}
+
+// DesignDay is a synthetic day that peaks at Peak around 14:00 and falls by
+// Range to its minimum around 05:00.
+type DesignDay struct {
+	Peak  float64 `json:"peak"`  // °C
+	Range float64 `json:"range"` // K
+}
+
+func (dc DesignConditions) Day() DesignDay {
+	return DesignDay{Peak: dc.DryBulb, Range: dc.DailyRange}
+}
+
+// Hourly returns the temperature at each clock hour, index 0 is midnight.
+func (d DesignDay) Hourly() [24]float64 {
+	var temps [24]float64
+	for i, f := range ashraeRangeFractions {
+		temps[(i+1)%24] = d.Peak - f*d.Range
+	}
+	return temps
+}
+
+// Daytime returns the hourly temperatures from 05:00 to 19:00, the span the
+// simulation covers.
+func (d DesignDay) Daytime() []float64 {
+	hourly := d.Hourly()
+	return append([]float64(nil), hourly[5:20]...)
+}
+
+// Profile interpolates the day to the per-minute simulation input.
+func (d DesignDay) Profile() ([840]float64, error) {
+	day, _, err := DaytimeProfile(d.Daytime())
+	return day, err
+}
+
+// DaytimeProfile checks hourly temperatures from 05:00, such as a Daytime of
+// a record, against quality.Default and interpolates them to the per-minute
+// simulation input with interop.Default.
+func DaytimeProfile(hourly []float64) ([840]float64, quality.Report, error) {
+	minutes, temps, report, err := quality.Check(interop.HourlyMinutes(len(hourly)), hourly, quality.Default)
+	if err != nil {
+		return [840]float64{}, report, err
+	}
+	day, err := interop.Default.Day(minutes, temps)
+	return day, report, err
+}
+
+// Daytime returns the observed temperatures from 05:00 to 19:00 on the first
+// day of the record matching month and day, e.g. a day of a TMY file.
+func (r Record) Daytime(month time.Month, day int) ([]float64, error) {
+	var temps []float64
+	for _, h := range r.Hours {
+		if h.Time.Month() != month || h.Time.Day() != day {
+			if len(temps) > 0 {
+				break
+			}
+			continue
+		}
+		if hour := h.Time.Hour(); hour >= 5 && hour <= 19 {
+			temps = append(temps, h.Temp)
+		}
+	}
+	if len(temps) != 15 {
+		return nil, fmt.Errorf("%s %d: found %d of the 15 hours from 05:00 to 19:00", month, day, len(temps))
+	}
+	return temps, nil
+}
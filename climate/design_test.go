@@ -0,0 +1,192 @@
+package climate
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func near(a, b float64) bool { return math.Abs(a-b) <= 1e-9 }
+
+func TestPercentile(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4, 5}
+	tests := []struct{ p, want float64 }{
+		{0, 1}, {50, 3}, {90, 4.6}, {99.6, 4.984}, {100, 5},
+	}
+	for _, tt := range tests {
+		if got := percentile(sorted, tt.p); !near(got, tt.want) {
+			t.Errorf("P%g is %g, want %g", tt.p, got, tt.want)
+		}
+	}
+}
+
+// syntheticYear is 2023 with days at 20 °C overnight and 30 °C from 08:00 to
+// 20:00, except four hot days from 10 April at 34 °C and 40 °C from 08:00 to
+// 18:00.
+func syntheticYear() Record {
+	var rec Record
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	for h := range 365 * 24 {
+		at := start.Add(time.Duration(h) * time.Hour)
+		hot := at.Month() == time.April && at.Day() >= 10 && at.Day() <= 13
+		temp := 20.0
+		switch {
+		case hot && at.Hour() >= 8 && at.Hour() < 18:
+			temp = 40
+		case hot:
+			temp = 34
+		case at.Hour() >= 8 && at.Hour() < 20:
+			temp = 30
+		}
+		rec.Hours = append(rec.Hours, Hour{Time: at, Temp: temp})
+	}
+	return rec
+}
+
+func TestDesign(t *testing.T) {
+	tests := []struct {
+		percent    float64
+		dryBulb    float64
+		designDays int
+		dailyRange float64
+	}{
+		// 40 hours at 40 °C cover the top 0.4 % (35 hours)
+		{0.4, 40, 4, 6},
+		// the top 1 % (88 hours) reaches into the 34 °C nights of the hot days
+		{1, 34, 4, 6},
+		// every day reaches the 2 % value, so the range mixes both kinds
+		{2, 30, 365, (361*10 + 4*6) / 365.0},
+	}
+	rec := syntheticYear()
+	for _, tt := range tests {
+		dc, err := Design(rec, tt.percent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dc.DryBulb != tt.dryBulb || dc.DesignDays != tt.designDays || !near(dc.DailyRange, tt.dailyRange) {
+			t.Errorf("%g %%: %g °C on %d days with a %g K range, want %g °C on %d days with %g K",
+				tt.percent, dc.DryBulb, dc.DesignDays, dc.DailyRange, tt.dryBulb, tt.designDays, tt.dailyRange)
+		}
+		if dc.HottestMonth != time.April || !near(dc.HottestMonthRange, (26*10+4*6)/30.0) || dc.Hours != 8760 {
+			t.Errorf("%g %%: hottest month %v with a %g K range over %d hours", tt.percent, dc.HottestMonth, dc.HottestMonthRange, dc.Hours)
+		}
+	}
+
+	for _, p := range []float64{0, 50} {
+		if _, err := Design(rec, p); err == nil {
+			t.Errorf("%g %% should be refused", p)
+		}
+	}
+	if _, err := Design(Record{Hours: rec.Hours[:24*7]}, 0.4); err == nil {
+		t.Error("a week of data should be refused")
+	}
+}
+
+func TestDesignDayHourly(t *testing.T) {
+	d := DesignDay{Peak: 35, Range: 10}
+	hourly := d.Hourly()
+
+	// the table starts at clock hour 1, so hour 24 is midnight
+	tests := []struct {
+		hour int
+		want float64
+	}{
+		{0, 35 - 0.82*10},
+		{1, 35 - 0.88*10},
+		{5, 25}, // the minimum
+		{13, 35 - 0.05*10},
+		{14, 35}, // the peak
+		{15, 35},
+		{19, 35 - 0.39*10},
+		{23, 35 - 0.75*10},
+	}
+	for _, tt := range tests {
+		if !near(hourly[tt.hour], tt.want) {
+			t.Errorf("%02d:00 is %g °C, want %g", tt.hour, hourly[tt.hour], tt.want)
+		}
+	}
+
+	daytime := d.Daytime()
+	if len(daytime) != 15 || daytime[0] != hourly[5] || daytime[14] != hourly[19] {
+		t.Errorf("daytime %v, want 05:00 to 19:00 of %v", daytime, hourly)
+	}
+
+	profile, err := d.Profile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the interpolation may round the peak off slightly
+	if !near(profile[0], 25) || math.Abs(profile[9*60]-35) > 0.5 {
+		t.Errorf("profile %g °C at 05:00 and %g at 14:00, want 25 and about 35", profile[0], profile[9*60])
+	}
+}
+
+func TestLoadEPW(t *testing.T) {
+	rec, err := LoadEPW("testdata/january.epw")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loc := rec.Location
+	if loc.City != "Bangkok" || loc.WMO != "484560" || loc.Lat != 13.73 || loc.TimeZone != 7 {
+		t.Errorf("location %+v", loc)
+	}
+	// 29 days less the five hours of the 3rd with a missing dry bulb
+	if len(rec.Hours) != 29*24-5 {
+		t.Fatalf("%d hours, want %d", len(rec.Hours), 29*24-5)
+	}
+
+	first := rec.Hours[0]
+	if want := time.Date(1995, 1, 1, 0, 0, 0, 0, time.FixedZone("LST", 7*3600)); !first.Time.Equal(want) || first.Temp != 24 {
+		t.Errorf("first hour %v at %g °C, want %v at 24", first.Time, first.Temp, want)
+	}
+	if first.Pressure != 1011 || first.Clouds != 50 || first.WindSpeed != 2.5 {
+		t.Errorf("pressure %g hPa, clouds %g %%, wind %g m/s, want 1011, 50 and 2.5", first.Pressure, first.Clouds, first.WindSpeed)
+	}
+	missing := rec.Hours[24]
+	for name, v := range map[string]float64{"dew point": missing.DewPoint, "humidity": missing.Humidity, "pressure": missing.Pressure, "wind": missing.WindSpeed, "clouds": missing.Clouds} {
+		if !math.IsNaN(v) {
+			t.Errorf("missing %s read as %g", name, v)
+		}
+	}
+	if h := rec.Hours[48]; h.Time.Day() != 3 || h.Time.Hour() != 5 {
+		t.Errorf("the 3rd starts at %v, want 05:00 after the missing hours", h.Time)
+	}
+
+	day, err := rec.Daytime(time.January, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if day[0] != 28 || day[5] != 38 || day[14] != 28 {
+		t.Errorf("10 January daytime %v", day)
+	}
+	if _, err := rec.Daytime(time.February, 1); err == nil {
+		t.Error("a day outside the file should fail")
+	}
+}
+
+func TestDesignEPW(t *testing.T) {
+	rec, err := LoadEPW("testdata/january.epw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// complete days: 27 with an 8 K range and the 10th with 10 K
+	monthRange := (27*8 + 10) / 28.0
+
+	// only the partial 3rd reaches 39 °C, so the month's range stands in
+	dc, err := Design(rec, 0.4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dc.DryBulb != 39 || dc.DesignDays != 0 || !near(dc.DailyRange, monthRange) || !near(dc.HottestMonthRange, monthRange) {
+		t.Errorf("0.4 %%: %+v, want 39 °C with the %g K month range", dc, monthRange)
+	}
+
+	dc, err = Design(rec, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dc.DryBulb != 38 || dc.DesignDays != 1 || dc.DailyRange != 10 {
+		t.Errorf("2 %%: %+v, want 38 °C on the 10th with its 10 K range", dc)
+	}
+}
@@ -0,0 +1,249 @@
+// Package climate reads long weather records such as EPW typical
+// meteorological years and derives design conditions from them.
+package climate
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Location struct {
+	City      string  `json:"city"`
+	Region    string  `json:"region"`
+	Country   string  `json:"country"`
+	Source    string  `json:"source"`
+	WMO       string  `json:"wmo"`
+	Lat       float64 `json:"lat"`
+	Lon       float64 `json:"lon"`
+	TimeZone  float64 `json:"time_zone"` // hours from UTC
+	Elevation float64 `json:"elevation"` // m
+}
+
+// Hour is one hourly observation. Time is the start of the hour in local
+// standard time. Variables the source does not carry are NaN.
+type Hour struct {
+	Time      time.Time `json:"time"`
+	Temp      float64   `json:"temp"`       // dry bulb, °C
+	DewPoint  float64   `json:"dew_point"`  // °C
+	Humidity  float64   `json:"humidity"`   // %
+	Pressure  float64   `json:"pressure"`   // station, hPa
+	WindSpeed float64   `json:"wind_speed"` // m/s
+	WindDeg   float64   `json:"wind_deg"`
+	Clouds    float64   `json:"clouds"` // total sky cover, %
+}
+
+// Record is a series of hourly observations, usually one year.
+type Record struct {
+	Location Location `json:"location"`
+	Hours    []Hour   `json:"hours"`
+}
+
+// EPW data columns, zero based
+const (
+	epwYear = iota
+	epwMonth
+	epwDay
+	epwHour // 1 to 24, the hour ending at this time
+	epwMinute
+	epwSource
+	epwDryBulb
+	epwDewPoint
+	epwHumidity
+	epwPressure // Pa
+)
+
+const (
+	epwWindDeg   = 20
+	epwWindSpeed = 21
+	epwSkyCover  = 22 // tenths
+)
+
+// EPW missing value markers
+var epwMissing = map[int]float64{
+	epwDryBulb:   99.9,
+	epwDewPoint:  99.9,
+	epwHumidity:  999,
+	epwPressure:  999999,
+	epwWindDeg:   999,
+	epwWindSpeed: 999,
+	epwSkyCover:  99,
+}
+
+// LoadEPW reads an EnergyPlus weather file.
+func LoadEPW(path string) (Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Record{}, err
+	}
+	defer f.Close()
+
+	rec, err := ReadEPW(f)
+	if err != nil {
+		return Record{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return rec, nil
+}
+
+// ReadEPW parses the LOCATION header and the hourly data rows of an EPW
+// file. Rows whose dry bulb temperature is missing are skipped.
+func ReadEPW(r io.Reader) (Record, error) {
+	cr := csv.NewReader(bufio.NewReader(r))
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	var rec Record
+	line := 0
+	for {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Record{}, err
+		}
+		line++
+
+		if line <= 8 {
+			// eight header lines, only the location is used
+			if strings.EqualFold(fields[0], "LOCATION") {
+				rec.Location = parseLocation(fields)
+			}
+			continue
+		}
+		if len(fields) <= epwSkyCover {
+			return Record{}, fmt.Errorf("line %d: %d fields, expected at least %d", line, len(fields), epwSkyCover+1)
+		}
+
+		num := func(col int) (float64, error) {
+			v, err := strconv.ParseFloat(strings.TrimSpace(fields[col]), 64)
+			if err != nil {
+				return 0, fmt.Errorf("line %d: field %d: %w", line, col+1, err)
+			}
+			if missing, ok := epwMissing[col]; ok && v >= missing {
+				return nan, nil
+			}
+			return v, nil
+		}
+
+		var date [4]int
+		for i := range date {
+			v, err := strconv.Atoi(strings.TrimSpace(fields[i]))
+			if err != nil {
+				return Record{}, fmt.Errorf("line %d: field %d: %w", line, i+1, err)
+			}
+			date[i] = v
+		}
+
+		var values [7]float64
+		for i, col := range []int{epwDryBulb, epwDewPoint, epwHumidity, epwPressure, epwWindSpeed, epwWindDeg, epwSkyCover} {
+			if values[i], err = num(col); err != nil {
+				return Record{}, err
+			}
+		}
+		if math.IsNaN(values[0]) {
+			continue
+		}
+
+		zone := time.FixedZone("LST", int(rec.Location.TimeZone*3600))
+		rec.Hours = append(rec.Hours, Hour{
+			// hour 1 covers 00:00 to 01:00
+			Time:      time.Date(date[epwYear], time.Month(date[epwMonth]), date[epwDay], date[epwHour]-1, 0, 0, 0, zone),
+			Temp:      values[0],
+			DewPoint:  values[1],
+			Humidity:  values[2],
+			Pressure:  values[3] / 100,
+			WindSpeed: values[4],
+			WindDeg:   values[5],
+			Clouds:    values[6] * 10,
+		})
+	}
+
+	if len(rec.Hours) == 0 {
+		return Record{}, errors.New("no hourly data found")
+	}
+	return rec, nil
+}
+
+// LOCATION,City,State,Country,Source,WMO,Latitude,Longitude,TimeZone,Elevation
+func parseLocation(fields []string) Location {
+	get := func(i int) string {
+		if i < len(fields) {
+			return strings.TrimSpace(fields[i])
+		}
+		return ""
+	}
+	num := func(i int) float64 {
+		v, _ := strconv.ParseFloat(get(i), 64)
+		return v
+	}
+	return Location{
+		City:      get(1),
+		Region:    get(2),
+		Country:   get(3),
+		Source:    get(4),
+		WMO:       get(5),
+		Lat:       num(6),
+		Lon:       num(7),
+		TimeZone:  num(8),
+		Elevation: num(9),
+	}
+}
+
+// LoadHourlyCSV reads historical observations from rows of "timestamp,temp",
+// timestamps in RFC 3339 or "2006-01-02 15:04". A header row is skipped.
+func LoadHourlyCSV(path string) (Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Record{}, err
+	}
+	defer f.Close()
+
+	cr := csv.NewReader(f)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return Record{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var rec Record
+	for i, row := range rows {
+		if len(row) < 2 {
+			continue
+		}
+		t, terr := parseTimestamp(row[0])
+		v, verr := strconv.ParseFloat(strings.TrimSpace(row[len(row)-1]), 64)
+		if terr != nil || verr != nil {
+			if i == 0 {
+				// header
+				continue
+			}
+			return Record{}, fmt.Errorf("%s: line %d: %w", path, i+1, errors.Join(terr, verr))
+		}
+		rec.Hours = append(rec.Hours, Hour{
+			Time: t, Temp: v,
+			DewPoint: nan, Humidity: nan, Pressure: nan, WindSpeed: nan, WindDeg: nan, Clouds: nan,
+		})
+	}
+
+	if len(rec.Hours) == 0 {
+		return Record{}, fmt.Errorf("%s: %w", path, errors.New("no hourly data found"))
+	}
+	return rec, nil
+}
+
+func parseTimestamp(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02 15:04", s, time.Local)
+}
@@ -0,0 +1,200 @@
+package climate
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DegreeDays at one base temperature, in K·day.
+type DegreeDays struct {
+	Base    float64 `json:"base"` // °C
+	Cooling float64 `json:"cooling"`
+	Heating float64 `json:"heating"`
+}
+
+// Stats describes the temperatures of a month or of the whole record.
+// Diurnal values only count days with most of their hours, unless no day has
+// them as in daytime-only records.
+type Stats struct {
+	Days  int `json:"days"`
+	Hours int `json:"hours"`
+
+	Mean float64 `json:"mean"` // °C
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+
+	MeanDailyMax float64 `json:"mean_daily_max"`
+	MeanDailyMin float64 `json:"mean_daily_min"`
+	DiurnalRange float64 `json:"diurnal_range"` // mean daily max - min, K
+
+	DegreeDays []DegreeDays `json:"degree_days"`
+}
+
+type MonthStats struct {
+	Month time.Month `json:"month"`
+	Stats
+}
+
+type Summary struct {
+	Annual Stats        `json:"annual"`
+	Months []MonthStats `json:"months"`
+}
+
+type dayHours struct {
+	date  string
+	month time.Month
+	temps []float64
+}
+
+// Summarize computes monthly and overall statistics with cooling and heating
+// degree-days at each base temperature. Degree-days come from the hourly
+// values: each day adds the mean of max(T - base, 0) over the hours it has,
+// so days with gaps, or daytime-only records, still count as whole days.
+func Summarize(rec Record, bases []float64) Summary {
+	var days []*dayHours
+	byDate := map[string]*dayHours{}
+	for _, h := range rec.Hours {
+		key := h.Time.Format(time.DateOnly)
+		d, ok := byDate[key]
+		if !ok {
+			d = &dayHours{date: key, month: h.Time.Month()}
+			byDate[key] = d
+			days = append(days, d)
+		}
+		d.temps = append(d.temps, h.Temp)
+	}
+	slices.SortFunc(days, func(a, b *dayHours) int { return strings.Compare(a.date, b.date) })
+
+	s := Summary{Annual: stats(days, bases)}
+	for m := time.January; m <= time.December; m++ {
+		var month []*dayHours
+		for _, d := range days {
+			if d.month == m {
+				month = append(month, d)
+			}
+		}
+		if len(month) > 0 {
+			s.Months = append(s.Months, MonthStats{Month: m, Stats: stats(month, bases)})
+		}
+	}
+	return s
+}
+
+func stats(days []*dayHours, bases []float64) Stats {
+	st := Stats{Min: math.Inf(1), Max: math.Inf(-1), Days: len(days)}
+	for _, b := range bases {
+		st.DegreeDays = append(st.DegreeDays, DegreeDays{Base: b})
+	}
+
+	sum := 0.0
+	var complete, partial struct {
+		max, min float64
+		days     int
+	}
+	for _, d := range days {
+		dayMin, dayMax := math.Inf(1), math.Inf(-1)
+		for _, t := range d.temps {
+			sum += t
+			dayMin = math.Min(dayMin, t)
+			dayMax = math.Max(dayMax, t)
+		}
+		st.Hours += len(d.temps)
+		st.Min = math.Min(st.Min, dayMin)
+		st.Max = math.Max(st.Max, dayMax)
+
+		for i := range st.DegreeDays {
+			cooling, heating := 0.0, 0.0
+			for _, t := range d.temps {
+				cooling += math.Max(t-st.DegreeDays[i].Base, 0)
+				heating += math.Max(st.DegreeDays[i].Base-t, 0)
+			}
+			st.DegreeDays[i].Cooling += cooling / float64(len(d.temps))
+			st.DegreeDays[i].Heating += heating / float64(len(d.temps))
+		}
+
+		partial.max += dayMax
+		partial.min += dayMin
+		partial.days++
+		if len(d.temps) >= minHoursPerDay {
+			complete.max += dayMax
+			complete.min += dayMin
+			complete.days++
+		}
+	}
+
+	if st.Hours > 0 {
+		st.Mean = sum / float64(st.Hours)
+	}
+	if complete.days == 0 {
+		complete = partial
+	}
+	if complete.days > 0 {
+		st.MeanDailyMax = complete.max / float64(complete.days)
+		st.MeanDailyMin = complete.min / float64(complete.days)
+		st.DiurnalRange = st.MeanDailyMax - st.MeanDailyMin
+	}
+	return st
+}
+
+// FromDay turns a simulated day of per-minute outside temperatures from
+// 05:00 into an hourly record starting on the day of start.
+func FromDay(start time.Time, temps [840]float64) Record {
+	first := time.Date(start.Year(), start.Month(), start.Day(), 5, 0, 0, 0, start.Location())
+	var rec Record
+	for m := 0; m < len(temps); m += 60 {
+		rec.Hours = append(rec.Hours, Hour{
+			Time: first.Add(time.Duration(m) * time.Minute), Temp: temps[m],
+			DewPoint: nan, Humidity: nan, Pressure: nan, WindSpeed: nan, WindDeg: nan, Clouds: nan,
+		})
+	}
+	return rec
+}
+
+// CoolingEnergy is the steady-state heat in kWh that an AC must remove from
+// an envelope of conductance ua (W/K) over cdd cooling degree-days. The
+// simulation bills the cooling power itself, so this is also the energy.
+func CoolingEnergy(ua, cdd float64) float64 {
+	return ua * cdd * 24 / 1000
+}
+
+// ScheduledCoolingEnergy is the degree-hour estimate of one day's AC energy
+// in kWh: the conduction gain above setTemp while the AC runs, minutes
+// [on, off) after 05:00, ignoring the heat stored in the room.
+func ScheduledCoolingEnergy(ua float64, outside [840]float64, setTemp float64, on, off int) float64 {
+	degreeMinutes := 0.0
+	for m := max(on, 0); m < min(off, len(outside)); m++ {
+		degreeMinutes += math.Max(outside[m]-setTemp, 0)
+	}
+	return ua * degreeMinutes / 60 / 1000
+}
+
+// WriteText prints the summary as a table, the layout used by the CLI and
+// the GUI.
+func (s Summary) WriteText(w io.Writer) error {
+	header := fmt.Sprintf("%-5s %4s %6s %6s %6s %6s", "Month", "Days", "Mean", "Min", "Max", "Range")
+	for _, dd := range s.Annual.DegreeDays {
+		header += fmt.Sprintf(" %8s %8s", fmt.Sprintf("CDD%g", dd.Base), fmt.Sprintf("HDD%g", dd.Base))
+	}
+	if _, err := fmt.Fprintln(w, header); err != nil {
+		return err
+	}
+
+	row := func(label string, st Stats) error {
+		line := fmt.Sprintf("%-5s %4d %6.1f %6.1f %6.1f %6.1f", label, st.Days, st.Mean, st.Min, st.Max, st.DiurnalRange)
+		for _, dd := range st.DegreeDays {
+			line += fmt.Sprintf(" %8.1f %8.1f", dd.Cooling, dd.Heating)
+		}
+		_, err := fmt.Fprintln(w, line)
+		return err
+	}
+	for _, m := range s.Months {
+		if err := row(m.Month.String()[:3], m.Stats); err != nil {
+			return err
+		}
+	}
+	return row("All", s.Annual)
+}
@@ -0,0 +1,161 @@
+package climate
+
+import (
+	"testing"
+	"time"
+)
+
+// hours returns a record of the temperatures hour by hour from start.
+func hours(start time.Time, temps ...float64) Record {
+	var rec Record
+	for i, t := range temps {
+		rec.Hours = append(rec.Hours, Hour{Time: start.Add(time.Duration(i) * time.Hour), Temp: t})
+	}
+	return rec
+}
+
+func repeat(v float64, n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = v
+	}
+	return out
+}
+
+func TestDegreeDays(t *testing.T) {
+	jan1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	split := append(repeat(20, 12), repeat(30, 12)...)
+
+	tests := []struct {
+		name             string
+		rec              Record
+		base             float64
+		cooling, heating float64
+	}{
+		{"constant above the base", hours(jan1, repeat(30, 3*24)...), 26, 12, 0},
+		{"constant below the base", hours(jan1, repeat(30, 3*24)...), 32, 0, 6},
+		{"constant at the base", hours(jan1, repeat(30, 3*24)...), 30, 0, 0},
+		{"half a day each side", hours(jan1, split...), 25, 2.5, 2.5},
+		{"asymmetric split", hours(jan1, split...), 22, 4, 1},
+		// a daytime-only day still counts as one day
+		{"partial day", hours(jan1.Add(8*time.Hour), repeat(30, 12)...), 25, 5, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dd := Summarize(tt.rec, []float64{tt.base}).Annual.DegreeDays[0]
+			if dd.Base != tt.base || !near(dd.Cooling, tt.cooling) || !near(dd.Heating, tt.heating) {
+				t.Errorf("%+v, want %g cooling and %g heating K·day", dd, tt.cooling, tt.heating)
+			}
+		})
+	}
+}
+
+func TestSummarizeMonths(t *testing.T) {
+	// 30 January to 1 February, warmer by a degree each day
+	var temps []float64
+	for d := range 3 {
+		for h := range 24 {
+			v := 25.0 + float64(d)
+			if h >= 10 && h < 18 {
+				v += 8
+			}
+			temps = append(temps, v)
+		}
+	}
+	s := Summarize(hours(time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC), temps...), []float64{18, 24})
+
+	if len(s.Months) != 2 || s.Months[0].Month != time.January || s.Months[1].Month != time.February {
+		t.Fatalf("months %+v, want January and February", s.Months)
+	}
+	jan, feb := s.Months[0], s.Months[1]
+	if jan.Days != 2 || jan.Hours != 48 || feb.Days != 1 || s.Annual.Days != 3 || s.Annual.Hours != 72 {
+		t.Errorf("January %d days, February %d, all %d, want 2, 1 and 3", jan.Days, feb.Days, s.Annual.Days)
+	}
+	if jan.Min != 25 || jan.Max != 34 || feb.Min != 27 || feb.Max != 35 {
+		t.Errorf("January %g to %g, February %g to %g", jan.Min, jan.Max, feb.Min, feb.Max)
+	}
+	if !near(jan.MeanDailyMax, 33.5) || !near(s.Annual.DiurnalRange, 8) {
+		t.Errorf("January mean daily max %g, diurnal range %g, want 33.5 and 8", jan.MeanDailyMax, s.Annual.DiurnalRange)
+	}
+	for i, dd := range s.Annual.DegreeDays {
+		sum := 0.0
+		for _, m := range s.Months {
+			sum += m.DegreeDays[i].Cooling
+		}
+		if !near(sum, dd.Cooling) {
+			t.Errorf("monthly CDD%g add up to %g, annual %g", dd.Base, sum, dd.Cooling)
+		}
+	}
+	// each day's mean is its overnight value plus 8 K for a third of it
+	if want := (25.0 + 26 + 27) + 3*8.0/3 - 3*18; !near(s.Annual.DegreeDays[0].Cooling, want) {
+		t.Errorf("CDD18 %g, want %g", s.Annual.DegreeDays[0].Cooling, want)
+	}
+}
+
+func TestSummarizePartialDays(t *testing.T) {
+	jan1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// a complete day ranging 10 K and a daytime-only day ranging 2 K: only
+	// the complete day counts towards the diurnal range
+	rec := hours(jan1, append(repeat(20, 12), repeat(30, 12)...)...)
+	rec.Hours = append(rec.Hours, hours(jan1.Add(24*time.Hour+8*time.Hour), append(repeat(28, 6), repeat(30, 6)...)...).Hours...)
+	st := Summarize(rec, nil).Annual
+	if st.Days != 2 || st.Hours != 36 || st.DiurnalRange != 10 || st.Max != 30 || st.Min != 20 {
+		t.Errorf("%+v, want two days with the complete day's 10 K range", st)
+	}
+
+	// without complete days the partial ones are used
+	st = Summarize(hours(jan1.Add(8*time.Hour), append(repeat(28, 6), repeat(30, 6)...)...), nil).Annual
+	if st.DiurnalRange != 2 || st.MeanDailyMax != 30 {
+		t.Errorf("%+v, want the daytime range of 2 K", st)
+	}
+}
+
+func TestFromDay(t *testing.T) {
+	var day [840]float64
+	for m := range day {
+		day[m] = 25 + float64(m)/60
+	}
+	rec := FromDay(time.Date(2024, 4, 15, 13, 30, 0, 0, time.UTC), day)
+	if len(rec.Hours) != 14 {
+		t.Fatalf("%d hours, want 14 from 05:00", len(rec.Hours))
+	}
+	if first := rec.Hours[0]; first.Time != time.Date(2024, 4, 15, 5, 0, 0, 0, time.UTC) || first.Temp != 25 {
+		t.Errorf("first hour %v at %g °C", first.Time, first.Temp)
+	}
+	if last := rec.Hours[13]; last.Time.Hour() != 18 || last.Temp != 38 {
+		t.Errorf("last hour %v at %g °C", last.Time, last.Temp)
+	}
+}
+
+func TestCoolingEnergy(t *testing.T) {
+	// 100 W/K over 10 K·day is 24 kWh
+	if got := CoolingEnergy(100, 10); !near(got, 24) {
+		t.Errorf("%g kWh, want 24", got)
+	}
+
+	var outside [840]float64
+	for m := range outside {
+		outside[m] = 30
+		if m >= 600 {
+			outside[m] = 20
+		}
+	}
+	tests := []struct {
+		name    string
+		on, off int
+		want    float64
+	}{
+		// 100 W/K at 4 K for two hours
+		{"two hours", 60, 180, 0.8},
+		// the cooler evening adds nothing
+		{"into the evening", 540, 720, 0.4},
+		{"clipped to the day", -10, 2000, 100 * 4 * 10.0 / 1000},
+		{"empty window", 300, 300, 0},
+	}
+	for _, tt := range tests {
+		if got := ScheduledCoolingEnergy(100, outside, 26, tt.on, tt.off); !near(got, tt.want) {
+			t.Errorf("%s: %g kWh, want %g", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,32 +1,282 @@
 package constants
 
-import "errors"
-
-// Thermal conductivity values in W/(m·K)
-var ThermalConductivity = map[string]float64{
-	"wood":       0.12,
-	"brick":      0.8,
-	"concrete":   1.8,
-	"fiberglass": 0.04,
-	"ps_foam":    0.035,
-	"pe_foam":    0.04,
-}
-
-// Average cost per cubic meter in Thai Baht (THB)
-var materialCosts = map[string]float64{
-	"wood":       22_500, // Average of 15,000 - 30,000 THB
-	"brick":      5_250,  // Average of 3,500 - 7,000 THB
-	"concrete":   4_000,  // Average of 3,000 - 5,000 THB
-	"fiberglass": 1_500,  // Average of 1,000 - 2,000 THB
-	"ps_foam":    2_250,  // Average of 1,500 - 3,000 THB
-	"pe_foam":    3_000,  // Average of 2,000 - 4,000 THB
-}
-
-// GetMaterialCost retrieves the average cost per cubic meter for a given material.
-func GetMaterialCost(material string) (float64, error) {
-	cost, exists := materialCosts[material]
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Material holds the thermophysical and cost properties of an envelope material.
+type Material struct {
+	Key          string     `json:"key"`
+	Name         string     `json:"name"`
+	Conductivity float64    `json:"conductivity"`  // W/(m·K)
+	Density      float64    `json:"density"`       // kg/m³
+	SpecificHeat float64    `json:"specific_heat"` // J/(kg·K)
+	Emissivity   float64    `json:"emissivity"`
+	Cost         PriceRange `json:"cost"` // per m³
+	Source       string     `json:"source"`
+	Date         string     `json:"date"`
+}
+
+// UnmarshalJSON also accepts the flat cost_per_m3 and currency fields of
+// material files written before costs were ranges. A typical cost in the cost
+// object takes precedence over cost_per_m3.
+func (m *Material) UnmarshalJSON(data []byte) error {
+	type material Material // without this method
+	legacy := struct {
+		*material
+		CostPerM3 *float64 `json:"cost_per_m3"`
+		Currency  string   `json:"currency"`
+	}{material: (*material)(m)}
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+	if legacy.CostPerM3 != nil && m.Cost.Typical == 0 {
+		m.Cost.Typical = *legacy.CostPerM3
+	}
+	if legacy.Currency != "" && m.Cost.Currency == "" {
+		m.Cost.Currency = legacy.Currency
+	}
+	return nil
+}
+
+var ErrMaterialNotFound = errors.New("material not found")
+
+// built-in defaults, costs are Thai market ranges
+var defaultMaterials = []Material{
+	{Key: "wood", Name: "Wood", Conductivity: 0.12, Density: 500, SpecificHeat: 1600, Emissivity: 0.90, Cost: thbRange(15_000, 30_000), Source: "built-in", Date: "2024"},
+	{Key: "brick", Name: "Lightweight bricks", Conductivity: 0.8, Density: 1600, SpecificHeat: 840, Emissivity: 0.93, Cost: thbRange(3_500, 7_000), Source: "built-in", Date: "2024"},
+	{Key: "concrete", Name: "Concrete", Conductivity: 1.8, Density: 2300, SpecificHeat: 880, Emissivity: 0.91, Cost: thbRange(3_000, 5_000), Source: "built-in", Date: "2024"},
+	{Key: "fiberglass", Name: "Fibre glass", Conductivity: 0.04, Density: 16, SpecificHeat: 840, Emissivity: 0.90, Cost: thbRange(1_000, 2_000), Source: "built-in", Date: "2024"},
+	{Key: "ps_foam", Name: "PS foam", Conductivity: 0.035, Density: 20, SpecificHeat: 1300, Emissivity: 0.90, Cost: thbRange(1_500, 3_000), Source: "built-in", Date: "2024"},
+	{Key: "pe_foam", Name: "PE foam", Conductivity: 0.04, Density: 30, SpecificHeat: 2300, Emissivity: 0.90, Cost: thbRange(2_000, 4_000), Source: "built-in", Date: "2024"},
+}
+
+func thbRange(min, max float64) PriceRange {
+	return PriceRange{Min: min, Typical: (min + max) / 2, Max: max, Currency: BaseCurrency, Region: "TH"}
+}
+
+var (
+	materialsMu   sync.RWMutex
+	materials     = map[string]Material{}
+	materialOrder []string
+)
+
+func init() {
+	for _, m := range defaultMaterials {
+		materials[m.Key] = m
+		materialOrder = append(materialOrder, m.Key)
+	}
+}
+
+// ListMaterials returns every known material, built-in defaults first.
+func ListMaterials() []Material {
+	materialsMu.RLock()
+	defer materialsMu.RUnlock()
+
+	list := make([]Material, 0, len(materialOrder))
+	for _, key := range materialOrder {
+		list = append(list, materials[key])
+	}
+	return list
+}
+
+// GetMaterial looks up a material by key.
+func GetMaterial(key string) (Material, error) {
+	materialsMu.RLock()
+	defer materialsMu.RUnlock()
+
+	m, exists := materials[key]
 	if !exists {
-		return 0, errors.New("material not found")
+		return Material{}, ErrMaterialNotFound
+	}
+	return m, nil
+}
+
+// AddMaterial adds a material to the database, replacing any existing entry with the same key.
+func AddMaterial(m Material) error {
+	m, err := checkMaterial(m)
+	if err != nil {
+		return err
+	}
+
+	materialsMu.Lock()
+	defer materialsMu.Unlock()
+	addMaterial(m)
+	return nil
+}
+
+// checkMaterial validates m and fills in its defaults.
+func checkMaterial(m Material) (Material, error) {
+	m.Key = strings.TrimSpace(m.Key)
+	if m.Key == "" {
+		return m, errors.New("material key is required")
+	}
+	if m.Conductivity <= 0 {
+		return m, fmt.Errorf("material %q: conductivity must be greater than zero", m.Key)
+	}
+	if m.Density < 0 || m.SpecificHeat < 0 {
+		return m, fmt.Errorf("material %q: density and specific heat must not be negative", m.Key)
+	}
+	if m.Emissivity < 0 || m.Emissivity > 1 {
+		return m, fmt.Errorf("material %q: emissivity must be between 0 and 1", m.Key)
+	}
+	if m.Name == "" {
+		m.Name = m.Key
+	}
+	cost, err := m.Cost.normalize()
+	if err != nil {
+		return m, fmt.Errorf("material %q: %w", m.Key, err)
+	}
+	m.Cost = cost
+	return m, nil
+}
+
+// addMaterial stores a checked material, materialsMu must be held.
+func addMaterial(m Material) {
+	if _, exists := materials[m.Key]; !exists {
+		materialOrder = append(materialOrder, m.Key)
+	}
+	materials[m.Key] = m
+}
+
+// LoadMaterials reads a .json or .csv material file and adds its entries to
+// the database, all of them or none.
+func LoadMaterials(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var list []Material
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		list, err = ReadMaterialsJSON(f)
+	case ".csv":
+		list, err = ReadMaterialsCSV(f)
+	default:
+		return fmt.Errorf("unsupported material file format: %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	// every row is checked first, a bad one leaves the database unchanged
+	for i, m := range list {
+		if list[i], err = checkMaterial(m); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	materialsMu.Lock()
+	defer materialsMu.Unlock()
+	for _, m := range list {
+		addMaterial(m)
+	}
+	return nil
+}
+
+// ReadMaterialsJSON decodes a JSON array of materials.
+func ReadMaterialsJSON(r io.Reader) ([]Material, error) {
+	var list []Material
+	if err := json.NewDecoder(r).Decode(&list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ReadMaterialsCSV decodes a CSV file whose header row uses the JSON field names.
+func ReadMaterialsCSV(r io.Reader) ([]Material, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("empty material file")
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.TrimSpace(strings.ToLower(name))] = i
+	}
+	if _, ok := columns["key"]; !ok {
+		return nil, errors.New("missing key column")
+	}
+
+	list := make([]Material, 0, len(records)-1)
+	for line, rec := range records[1:] {
+		get := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(rec) {
+				return ""
+			}
+			return strings.TrimSpace(rec[i])
+		}
+		num := func(name string) (float64, error) {
+			s := get(name)
+			if s == "" {
+				return 0, nil
+			}
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return 0, fmt.Errorf("line %d: %s: %w", line+2, name, err)
+			}
+			return v, nil
+		}
+
+		m := Material{
+			Key:    get("key"),
+			Name:   get("name"),
+			Source: get("source"),
+			Date:   get("date"),
+			Cost: PriceRange{
+				Currency: get("currency"),
+				Region:   get("region"),
+			},
+		}
+		// cost_per_m3 is accepted as the typical price for older files,
+		// cost_typical comes later and wins when both are given
+		for _, col := range []struct {
+			name string
+			dst  *float64
+		}{
+			{"conductivity", &m.Conductivity},
+			{"density", &m.Density},
+			{"specific_heat", &m.SpecificHeat},
+			{"emissivity", &m.Emissivity},
+			{"cost_min", &m.Cost.Min},
+			{"cost_per_m3", &m.Cost.Typical},
+			{"cost_typical", &m.Cost.Typical},
+			{"cost_max", &m.Cost.Max},
+		} {
+			if get(col.name) == "" {
+				continue
+			}
+			v, err := num(col.name)
+			if err != nil {
+				return nil, err
+			}
+			*col.dst = v
+		}
+		list = append(list, m)
+	}
+	return list, nil
+}
+
+// GetMaterialCost retrieves the cost range per cubic meter for a given material.
+func GetMaterialCost(material string) (PriceRange, error) {
+	m, err := GetMaterial(material)
+	if err != nil {
+		return PriceRange{}, err
 	}
-	return cost, nil
+	return m.Cost, nil
 }
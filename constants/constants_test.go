@@ -0,0 +1,79 @@
+package constants
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadMaterialsJSONCost(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want PriceRange
+	}{
+		{"range", `[{"key":"a","cost":{"min":1,"typical":2,"max":3,"currency":"USD"}}]`, PriceRange{Min: 1, Typical: 2, Max: 3, Currency: "USD"}},
+		{"legacy", `[{"key":"a","cost_per_m3":2500,"currency":"EUR"}]`, PriceRange{Typical: 2500, Currency: "EUR"}},
+		{"range wins", `[{"key":"a","cost_per_m3":2500,"currency":"EUR","cost":{"typical":2,"currency":"USD"}}]`, PriceRange{Typical: 2, Currency: "USD"}},
+		{"legacy fills in", `[{"key":"a","cost_per_m3":2500,"cost":{"region":"TH"}}]`, PriceRange{Typical: 2500, Region: "TH"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := ReadMaterialsJSON(strings.NewReader(tt.json))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(list) != 1 || list[0].Key != "a" {
+				t.Fatalf("materials %+v", list)
+			}
+			if list[0].Cost != tt.want {
+				t.Errorf("cost %+v, want %+v", list[0].Cost, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadMaterialsCSVCost(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		want float64
+	}{
+		{"typical", "key,conductivity,cost_typical\na,0.1,2", 2},
+		{"legacy", "key,conductivity,cost_per_m3\na,0.1,2500", 2500},
+		{"typical wins", "key,conductivity,cost_per_m3,cost_typical\na,0.1,2500,2", 2},
+		{"typical wins in any column order", "key,cost_typical,conductivity,cost_per_m3\na,2,0.1,2500", 2},
+		{"empty typical", "key,conductivity,cost_per_m3,cost_typical\na,0.1,2500,", 2500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// repeated, the columns used to be visited in map order
+			for range 20 {
+				list, err := ReadMaterialsCSV(strings.NewReader(tt.csv))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := list[0].Cost.Typical; got != tt.want {
+					t.Fatalf("typical cost %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadMaterialsAllOrNothing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "materials.csv")
+	data := "key,name,conductivity,cost_typical\nloaded_ok,Fine,0.1,100\nloaded_bad,Broken,0,100\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LoadMaterials(path); err == nil || !strings.Contains(err.Error(), "loaded_bad") {
+		t.Fatalf("error %v, want the bad row named", err)
+	}
+	if _, err := GetMaterial("loaded_ok"); !errors.Is(err, ErrMaterialNotFound) {
+		t.Errorf("the valid row was added from a file that failed to load")
+	}
+}
@@ -0,0 +1,207 @@
+package constants
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// BaseCurrency is the currency exchange rates are expressed against.
+const BaseCurrency = "THB"
+
+// PriceRange is a low/typical/high price quoted in one currency for one region.
+type PriceRange struct {
+	Min      float64 `json:"min"`
+	Typical  float64 `json:"typical"`
+	Max      float64 `json:"max"`
+	Currency string  `json:"currency"`
+	Region   string  `json:"region,omitempty"`
+}
+
+// RateTable is the on-disk format of an exchange rate file.
+// Rates are the number of base currency units per unit of each currency.
+type RateTable struct {
+	Base  string             `json:"base"`
+	Date  string             `json:"date,omitempty"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// approximate 2024 rates, override with LoadExchangeRates
+var (
+	ratesMu       sync.RWMutex
+	exchangeRates = map[string]float64{
+		"THB": 1,
+		"USD": 36.0,
+		"EUR": 39.0,
+		"GBP": 45.5,
+		"JPY": 0.24,
+		"CNY": 5.0,
+		"SGD": 26.8,
+		"MYR": 7.7,
+	}
+)
+
+// SetExchangeRate sets how many base currency units one unit of currency is worth.
+func SetExchangeRate(currency string, rate float64) error {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return errors.New("currency code is required")
+	}
+	if rate <= 0 {
+		return fmt.Errorf("exchange rate for %s must be greater than zero", currency)
+	}
+
+	ratesMu.Lock()
+	defer ratesMu.Unlock()
+	exchangeRates[currency] = rate
+	return nil
+}
+
+// LoadExchangeRates reads a JSON rate table and merges it into the current rates.
+func LoadExchangeRates(path string) error {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var table RateTable
+	if err := json.Unmarshal(buf, &table); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	// rebase onto THB if the file uses another base
+	factor := 1.0
+	if table.Base != "" && !strings.EqualFold(table.Base, BaseCurrency) {
+		r, ok := table.Rates[BaseCurrency]
+		if !ok || r <= 0 {
+			return fmt.Errorf("%s: rate table in %s must include %s", path, table.Base, BaseCurrency)
+		}
+		factor = 1 / r
+	}
+
+	for currency, rate := range table.Rates {
+		if err := SetExchangeRate(currency, rate*factor); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// Currencies lists every currency with a known exchange rate.
+func Currencies() []string {
+	ratesMu.RLock()
+	defer ratesMu.RUnlock()
+
+	list := make([]string, 0, len(exchangeRates))
+	for c := range exchangeRates {
+		list = append(list, c)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Convert converts an amount between two currencies.
+func Convert(amount float64, from, to string) (float64, error) {
+	from, to = normalizeCurrency(from), normalizeCurrency(to)
+	if from == to {
+		return amount, nil
+	}
+
+	ratesMu.RLock()
+	defer ratesMu.RUnlock()
+
+	fromRate, ok := exchangeRates[from]
+	if !ok {
+		return 0, fmt.Errorf("unknown currency: %s", from)
+	}
+	toRate, ok := exchangeRates[to]
+	if !ok {
+		return 0, fmt.Errorf("unknown currency: %s", to)
+	}
+	return amount * fromRate / toRate, nil
+}
+
+func normalizeCurrency(c string) string {
+	c = strings.ToUpper(strings.TrimSpace(c))
+	if c == "" {
+		return BaseCurrency
+	}
+	return c
+}
+
+// Convert returns the range expressed in another currency.
+func (p PriceRange) Convert(to string) (PriceRange, error) {
+	out := p
+	out.Currency = normalizeCurrency(to)
+	for _, v := range []*float64{&out.Min, &out.Typical, &out.Max} {
+		c, err := Convert(*v, p.Currency, to)
+		if err != nil {
+			return PriceRange{}, err
+		}
+		*v = c
+	}
+	return out, nil
+}
+
+// Scale multiplies every bound by f.
+func (p PriceRange) Scale(f float64) PriceRange {
+	p.Min *= f
+	p.Typical *= f
+	p.Max *= f
+	return p
+}
+
+// Add sums two ranges, converting q into p's currency.
+func (p PriceRange) Add(q PriceRange) (PriceRange, error) {
+	if p.Currency == "" {
+		p.Currency = q.Currency
+	}
+	q, err := q.Convert(p.Currency)
+	if err != nil {
+		return PriceRange{}, err
+	}
+	p.Min += q.Min
+	p.Typical += q.Typical
+	p.Max += q.Max
+	if p.Region != q.Region {
+		p.Region = ""
+	}
+	return p, nil
+}
+
+func (p PriceRange) String() string {
+	return fmt.Sprintf("%.2f - %.2f %s (typ. %.2f)", p.Min, p.Max, normalizeCurrency(p.Currency), p.Typical)
+}
+
+// fill in missing bounds and check ordering
+func (p PriceRange) normalize() (PriceRange, error) {
+	p.Currency = normalizeCurrency(p.Currency)
+	if p.Min < 0 || p.Typical < 0 || p.Max < 0 {
+		return p, errors.New("cost must not be negative")
+	}
+
+	switch {
+	case p.Typical == 0 && p.Max > 0:
+		p.Typical = (p.Min + p.Max) / 2
+	case p.Min == 0 && p.Max == 0:
+		p.Min, p.Max = p.Typical, p.Typical
+	}
+	if p.Max == 0 {
+		p.Max = p.Typical
+	}
+	if p.Min > p.Typical || p.Typical > p.Max {
+		return p, errors.New("cost range must satisfy min <= typical <= max")
+	}
+
+	ratesMu.RLock()
+	_, known := exchangeRates[p.Currency]
+	ratesMu.RUnlock()
+	if !known {
+		return p, fmt.Errorf("unknown currency: %s", p.Currency)
+	}
+	return p, nil
+}
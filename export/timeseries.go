@@ -0,0 +1,141 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"heat-transfer/scenario"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Point is one minute of a simulated day.
+type Point struct {
+	Timestamp      time.Time `json:"timestamp"`
+	Minute         int       `json:"minute"`
+	OutsideTemp    float64   `json:"outside_temp"` // °C
+	InsideTemp     float64   `json:"inside_temp"`  // °C
+	ACOn           bool      `json:"ac_on"`
+	ACPower        float64   `json:"ac_power"`        // W drawn while the compressor runs
+	CumulativeKWh  float64   `json:"cumulative_kwh"`  // since 05:00
+	CumulativeCost float64   `json:"cumulative_cost"` // THB since 05:00
+
+	Weather *Conditions `json:"weather,omitempty"`
+}
+
+// Conditions are the outside variables besides temperature at one minute.
+type Conditions struct {
+	Humidity  float64 `json:"humidity"`   // %
+	DewPoint  float64 `json:"dew_point"`  // °C
+	Clouds    float64 `json:"clouds"`     // %
+	WindSpeed float64 `json:"wind_speed"` // m/s
+	WindDeg   float64 `json:"wind_deg"`
+	UVI       float64 `json:"uvi"`
+	Pressure  float64 `json:"pressure"` // hPa
+}
+
+type TimeSeries struct {
+	Name     string    `json:"name"`
+	Start    time.Time `json:"start"`
+	Currency string    `json:"currency"`
+	Points   []Point   `json:"points"`
+}
+
+// DayStart returns 05:00 on the given day, the first simulated minute.
+func DayStart(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 5, 0, 0, 0, day.Location())
+}
+
+// FromResult builds the minute by minute series of a simulation result. Cost
+// is allocated to each minute at the average price per kWh of the monthly bill.
+func FromResult(r scenario.Result, start time.Time) TimeSeries {
+	ts := TimeSeries{
+		Name:     r.Scenario.Name,
+		Start:    start,
+		Currency: "THB",
+		Points:   make([]Point, 0, len(r.InsideTemps)),
+	}
+
+	power := 0.0
+	if r.Scenario.AC != nil {
+		power = math.Abs(r.Scenario.AC.CoolingPower)
+	}
+
+	// CalculateACCostForSimulation bills a 30 day month
+	pricePerKWh := 0.0
+	if r.DailyKWh > 0 {
+		pricePerKWh = r.MonthlyACCost / (r.DailyKWh * 30)
+	}
+
+	kwh := 0.0
+	for i, inside := range r.InsideTemps {
+		p := Point{
+			Timestamp:   start.Add(time.Duration(i) * time.Minute),
+			Minute:      i,
+			OutsideTemp: r.OutsideTemps[i],
+			InsideTemp:  inside,
+		}
+		if w := r.Weather; w != nil && i < len(w.Temp) {
+			p.Weather = &Conditions{
+				Humidity:  w.Humidity[i],
+				DewPoint:  w.DewPoint[i],
+				Clouds:    w.Clouds[i],
+				WindSpeed: w.WindSpeed[i],
+				WindDeg:   w.WindDeg[i],
+				UVI:       w.UVI[i],
+				Pressure:  w.Pressure[i],
+			}
+		}
+		if r.Cooling(i) {
+			p.ACOn = true
+			p.ACPower = power
+			kwh += power / 1000.0 / 60.0
+		}
+		p.CumulativeKWh = kwh
+		p.CumulativeCost = kwh * pricePerKWh
+		ts.Points = append(ts.Points, p)
+	}
+
+	return ts
+}
+
+func (ts TimeSeries) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	f := func(v float64, prec int) string { return strconv.FormatFloat(v, 'f', prec, 64) }
+
+	header := []string{"timestamp", "minute", "outside_temp_c", "inside_temp_c", "ac_on", "ac_power_w", "cumulative_kwh", "cumulative_cost_" + ts.Currency}
+	// weather columns only when the source had them
+	withWeather := len(ts.Points) > 0 && ts.Points[0].Weather != nil
+	if withWeather {
+		header = append(header, "humidity_pct", "dew_point_c", "clouds_pct", "wind_speed_ms", "wind_deg", "uvi", "pressure_hpa")
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, p := range ts.Points {
+		rec := []string{
+			p.Timestamp.Format(time.RFC3339), strconv.Itoa(p.Minute),
+			f(p.OutsideTemp, 3), f(p.InsideTemp, 3),
+			strconv.FormatBool(p.ACOn), f(p.ACPower, 0),
+			f(p.CumulativeKWh, 4), f(p.CumulativeCost, 4),
+		}
+		if withWeather && p.Weather != nil {
+			c := p.Weather
+			rec = append(rec, f(c.Humidity, 1), f(c.DewPoint, 2), f(c.Clouds, 1), f(c.WindSpeed, 2), f(c.WindDeg, 0), f(c.UVI, 2), f(c.Pressure, 1))
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func (ts TimeSeries) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(ts)
+}
@@ -0,0 +1,31 @@
+package export
+
+import (
+	"heat-transfer/calc"
+	"heat-transfer/scenario"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFromResultMasksACWindow(t *testing.T) {
+	// the compressor state stays on outside the window, where the solver
+	// applies no cooling
+	r := scenario.Result{
+		Scenario:     scenario.Scenario{AC: &calc.ACParams{Enabled: true, OnTime: 2, OffTime: 4, CoolingPower: -3000}},
+		InsideTemps:  []float64{30, 30, 30, 29, 28, 29},
+		OutsideTemps: []float64{32, 32, 32, 32, 32, 32},
+		ACRunning:    []bool{true, true, true, true, true, false},
+	}
+	ts := FromResult(r, time.Date(2024, 4, 15, 5, 0, 0, 0, time.UTC))
+
+	for i, want := range []bool{false, false, true, true, false, false} {
+		p := ts.Points[i]
+		if p.ACOn != want || (p.ACPower != 0) != want {
+			t.Errorf("minute %d: AC on %v at %g W, want on %v", i, p.ACOn, p.ACPower, want)
+		}
+	}
+	if kwh := ts.Points[5].CumulativeKWh; math.Abs(kwh-0.1) > 1e-12 {
+		t.Errorf("%g kWh, want two minutes at 3 kW", kwh)
+	}
+}
@@ -0,0 +1,235 @@
+package export
+
+import (
+	"fmt"
+	"heat-transfer/batch"
+	"heat-transfer/chartings"
+	"heat-transfer/scenario"
+	"math"
+	"strings"
+	"time"
+)
+
+// ScenarioWorkbook builds an inputs, time series and cost summary workbook
+// for one simulation, with the temperature chart on the time series sheet.
+func ScenarioWorkbook(r scenario.Result, start time.Time) (*Workbook, error) {
+	wb := NewWorkbook()
+
+	inputs := wb.AddSheet("Inputs")
+	inputs.AddHeader("Input", r.Scenario.Name)
+	for _, kv := range scenarioInputs(r) {
+		inputs.AddRow(kv.label, kv.value)
+	}
+
+	ts := FromResult(r, start)
+	series := wb.AddSheet("Time series")
+	series.AddHeader("Timestamp", "Minute", "Outside (°C)", "Inside (°C)", "AC on", "AC power (W)", "Cumulative kWh", "Cumulative cost ("+ts.Currency+")")
+	for _, p := range ts.Points {
+		series.AddRow(p.Timestamp, p.Minute, p.OutsideTemp, p.InsideTemp, p.ACOn, p.ACPower, p.CumulativeKWh, p.CumulativeCost)
+	}
+
+	chart, err := chartings.PlotComparison(chartings.XYs(r.TimeMinutes, r.OutsideTemps), []chartings.Series{
+		{Name: "Inside", Points: chartings.XYs(r.TimeMinutes, r.InsideTemps)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := series.AddImage(chart.Bytes(), 9, 1); err != nil {
+		return nil, err
+	}
+
+	costs := wb.AddSheet("Cost summary")
+	writeCostSummary(costs, r)
+
+	return wb, nil
+}
+
+// ComparisonWorkbook puts every scenario side by side, with the overlaid
+// temperature chart on the comparison sheet.
+func ComparisonWorkbook(c scenario.Comparison, start time.Time) (*Workbook, error) {
+	wb := NewWorkbook()
+
+	summary := wb.AddSheet("Comparison")
+	summary.AddHeader("Scenario", "U (W/m²K)", "Peak inside (°C)", "Δ peak (°C)", "AC minutes", "Δ AC minutes", "kWh/day", "AC cost/month (THB)", "Δ AC cost/month (THB)", "Material typical (THB)", "Δ material (THB)")
+	for i, r := range c.Results {
+		d := c.Deltas[i]
+		summary.AddRow(r.Scenario.Name, r.Coeff, r.PeakInsideTemp, d.PeakInsideTemp, r.ACMinutes, d.ACMinutes, r.DailyKWh, r.MonthlyACCost, d.MonthlyACCost, r.MaterialCost.Typical, d.MaterialCost)
+	}
+
+	if len(c.Finance) > 0 {
+		summary.AddRow()
+		summary.AddHeader("Upgrade", "Baseline", "Extra investment (THB)", "NPV (THB)", "Simple payback (years)", "Discounted payback (years)", "IRR")
+		for _, f := range c.Finance {
+			summary.AddRow(f.Upgrade.Name, f.Baseline.Name, f.ExtraInvestment, f.NPV, finite(f.SimplePayback), finite(f.DiscountedPayback), finite(f.IRR))
+		}
+	}
+
+	base := c.Results[0]
+	var lines []chartings.Series
+	for _, r := range c.Results {
+		lines = append(lines, chartings.Series{Name: r.Scenario.Name, Points: chartings.XYs(r.TimeMinutes, r.InsideTemps)})
+	}
+	chart, err := chartings.PlotComparison(chartings.XYs(base.TimeMinutes, base.OutsideTemps), lines)
+	if err != nil {
+		return nil, err
+	}
+	if err := summary.AddImage(chart.Bytes(), 0, summary.Rows()+1); err != nil {
+		return nil, err
+	}
+
+	inputs := wb.AddSheet("Inputs")
+	header := []any{"Input"}
+	columns := make([][]labelValue, len(c.Results))
+	for i, r := range c.Results {
+		header = append(header, r.Scenario.Name)
+		columns[i] = scenarioInputs(r)
+	}
+	inputs.AddHeader(header...)
+	for row := range columns[0] {
+		values := []any{columns[0][row].label}
+		for _, col := range columns {
+			values = append(values, col[row].value)
+		}
+		inputs.AddRow(values...)
+	}
+
+	series := wb.AddSheet("Time series")
+	header = []any{"Timestamp", "Minute", "Outside (°C)"}
+	for _, r := range c.Results {
+		header = append(header, r.Scenario.Name+" inside (°C)")
+	}
+	for _, r := range c.Results {
+		header = append(header, r.Scenario.Name+" AC on")
+	}
+	series.AddHeader(header...)
+	for m := range base.InsideTemps {
+		values := []any{start.Add(time.Duration(m) * time.Minute), m, base.OutsideTemps[m]}
+		for _, r := range c.Results {
+			values = append(values, r.InsideTemps[m])
+		}
+		for _, r := range c.Results {
+			values = append(values, m < len(r.ACRunning) && r.ACRunning[m])
+		}
+		series.AddRow(values...)
+	}
+
+	for _, r := range c.Results {
+		writeCostSummary(wb.AddSheet("Costs "+r.Scenario.Name), r)
+	}
+
+	return wb, nil
+}
+
+// BatchWorkbook writes a parameter sweep results table.
+func BatchWorkbook(rows []batch.Row) *Workbook {
+	wb := NewWorkbook()
+
+	results := wb.AddSheet("Results")
+	results.AddHeader("Index", "Material", "Thickness (m)", "Set temp (°C)", "AC power (W)", "Schedule",
+		"U (W/m²K)", "Peak inside (°C)", "Min inside (°C)", "AC minutes", "kWh/day", "AC cost/month (THB)",
+		"Material min (THB)", "Material typical (THB)", "Material max (THB)", "Error")
+	for _, r := range rows {
+		results.AddRow(r.Index, r.Point.Material, r.Point.Thickness, r.Point.SetTemp, r.Point.ACPower, r.Point.Schedule.String(),
+			r.Coeff, r.PeakInsideTemp, r.MinInsideTemp, r.ACMinutes, r.DailyKWh, r.MonthlyACCost,
+			r.MaterialMin, r.MaterialCost, r.MaterialMax, r.Err)
+	}
+
+	return wb
+}
+
+type labelValue struct {
+	label string
+	value any
+}
+
+func scenarioInputs(r scenario.Result) []labelValue {
+	s := r.Scenario
+
+	layers := make([]string, 0, len(s.Layers))
+	for _, l := range s.Layers {
+		layers = append(layers, fmt.Sprintf("%s %.3f m", l.Material, l.Thickness))
+	}
+
+	insideTemp := any("outside at 05:00")
+	if s.InsideTemp != nil {
+		insideTemp = *s.InsideTemp
+	}
+
+	list := []labelValue{
+		{"Width (m)", s.Width},
+		{"Height (m)", s.Height},
+		{"Depth (m)", s.Depth},
+		{"Layers", strings.Join(layers, ", ")},
+		{"U value (W/m²K)", r.Coeff},
+		{"Infiltration (ACH)", s.Infiltration},
+		{"Effective U incl. infiltration (W/m²K)", r.EffCoeff},
+		{"Roof insulated", s.Roof},
+		{"Floor insulated", s.Floor},
+		{"Initial inside temperature (°C)", insideTemp},
+		{"Existing usage (kWh/month)", s.ExistingUsage},
+	}
+
+	ac := calcAC(s)
+	list = append(list,
+		labelValue{"AC enabled", ac.enabled},
+		labelValue{"AC on", ac.on},
+		labelValue{"AC off", ac.off},
+		labelValue{"AC set temperature (°C)", ac.setTemp},
+		labelValue{"AC cooling power (W)", ac.power},
+	)
+	return list
+}
+
+type acInputs struct {
+	enabled        bool
+	on, off        string
+	setTemp, power float64
+}
+
+func calcAC(s scenario.Scenario) acInputs {
+	if s.AC == nil {
+		return acInputs{}
+	}
+	clock := func(m int) string { return fmt.Sprintf("%02d:%02d", m/60+5, m%60) }
+	return acInputs{
+		enabled: s.AC.Enabled,
+		on:      clock(s.AC.OnTime),
+		off:     clock(s.AC.OffTime),
+		setTemp: s.AC.SetTemp,
+		power:   math.Abs(s.AC.CoolingPower),
+	}
+}
+
+func writeCostSummary(sheet *Sheet, r scenario.Result) {
+	sheet.AddHeader("Item", "Value")
+	sheet.AddRow("Peak inside temperature (°C)", r.PeakInsideTemp)
+	sheet.AddRow("Minimum inside temperature (°C)", r.MinInsideTemp)
+	sheet.AddRow("AC runtime (minutes/day)", r.ACMinutes)
+	sheet.AddRow("AC energy (kWh/day)", r.DailyKWh)
+	sheet.AddRow("AC cost (THB/month)", r.MonthlyACCost)
+	sheet.AddRow("AC cost (THB/year)", r.MonthlyACCost*12)
+	sheet.AddRow()
+
+	sheet.AddHeader("Element", "Material", "Net area (m²)", "Net volume (m³)", "Order volume (m³)",
+		"Material min", "Material typical", "Material max", "Labour min", "Labour typical", "Labour max",
+		"Total min", "Total typical", "Total max", "Currency")
+	for _, it := range r.BOQ.Items {
+		sheet.AddRow(it.Element, it.Material, it.NetArea, it.NetVolume, it.OrderVolume,
+			it.MaterialCost.Min, it.MaterialCost.Typical, it.MaterialCost.Max,
+			it.LabourCost.Min, it.LabourCost.Typical, it.LabourCost.Max,
+			it.Total.Min, it.Total.Typical, it.Total.Max, it.Total.Currency)
+	}
+	b := r.BOQ
+	sheet.AddRow("Total", "", "", "", "",
+		b.MaterialTotal.Min, b.MaterialTotal.Typical, b.MaterialTotal.Max,
+		b.LabourTotal.Min, b.LabourTotal.Typical, b.LabourTotal.Max,
+		b.Total.Min, b.Total.Typical, b.Total.Max, r.MaterialCost.Currency)
+}
+
+// blank cell instead of an infinite payback or undefined IRR
+func finite(v float64) any {
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return nil
+	}
+	return v
+}
@@ -0,0 +1,320 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	_ "image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Workbook is a minimal XLSX writer: inline string, number, boolean and date
+// cells, a bold header style and PNG images anchored to a cell.
+type Workbook struct {
+	sheets []*Sheet
+}
+
+type Sheet struct {
+	Name   string
+	rows   []row
+	images []sheetImage
+}
+
+type row struct {
+	header bool
+	values []any
+}
+
+type sheetImage struct {
+	png           []byte
+	col, row      int
+	width, height int // px
+}
+
+const (
+	styleDefault = 0
+	styleHeader  = 1
+	styleDate    = 2
+)
+
+func NewWorkbook() *Workbook {
+	return &Workbook{}
+}
+
+// AddSheet appends a sheet. Names are cleaned up and made unique as Excel requires.
+func (wb *Workbook) AddSheet(name string) *Sheet {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" {
+		name = "Sheet"
+	}
+	if len([]rune(name)) > 31 {
+		name = string([]rune(name)[:31])
+	}
+
+	unique := name
+	for i := 2; wb.hasSheet(unique); i++ {
+		suffix := fmt.Sprintf(" (%d)", i)
+		base := []rune(name)
+		if len(base)+len(suffix) > 31 {
+			base = base[:31-len(suffix)]
+		}
+		unique = string(base) + suffix
+	}
+
+	s := &Sheet{Name: unique}
+	wb.sheets = append(wb.sheets, s)
+	return s
+}
+
+func (wb *Workbook) hasSheet(name string) bool {
+	for _, s := range wb.sheets {
+		if strings.EqualFold(s.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddHeader appends a bold row.
+func (s *Sheet) AddHeader(values ...any) {
+	s.rows = append(s.rows, row{header: true, values: values})
+}
+
+// AddRow appends a row of strings, numbers, bools or time.Time values.
+func (s *Sheet) AddRow(values ...any) {
+	s.rows = append(s.rows, row{values: values})
+}
+
+// Rows returns the number of rows written so far.
+func (s *Sheet) Rows() int {
+	return len(s.rows)
+}
+
+// AddImage anchors a PNG with its top left corner at the zero based cell.
+func (s *Sheet) AddImage(png []byte, col, row int) error {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(png))
+	if err != nil {
+		return fmt.Errorf("decode chart image: %w", err)
+	}
+	s.images = append(s.images, sheetImage{png: png, col: col, row: row, width: cfg.Width, height: cfg.Height})
+	return nil
+}
+
+func (wb *Workbook) Write(w io.Writer) error {
+	if len(wb.sheets) == 0 {
+		wb.AddSheet("Sheet1")
+	}
+
+	zw := zip.NewWriter(w)
+	add := func(name, content string) error {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(f, content)
+		return err
+	}
+
+	var types, sheets, workbookRels strings.Builder
+	types.WriteString(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Default Extension="png" ContentType="image/png"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+
+	imageCount := 0
+	for i, s := range wb.sheets {
+		n := i + 1
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		fmt.Fprintf(&sheets, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(s.Name), n, n)
+		fmt.Fprintf(&workbookRels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n)
+
+		if err := add(fmt.Sprintf("xl/worksheets/sheet%d.xml", n), sheetXML(s)); err != nil {
+			return err
+		}
+		if len(s.images) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&types, `<Override PartName="/xl/drawings/drawing%d.xml" ContentType="application/vnd.openxmlformats-officedocument.drawing+xml"/>`, n)
+		err := add(fmt.Sprintf("xl/worksheets/_rels/sheet%d.xml.rels", n), rels(
+			fmt.Sprintf(`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/drawing" Target="../drawings/drawing%d.xml"/>`, n),
+		))
+		if err != nil {
+			return err
+		}
+
+		var drawingRels []string
+		for j, img := range s.images {
+			imageCount++
+			media := fmt.Sprintf("image%d.png", imageCount)
+			f, err := zw.Create("xl/media/" + media)
+			if err != nil {
+				return err
+			}
+			if _, err := f.Write(img.png); err != nil {
+				return err
+			}
+			drawingRels = append(drawingRels, fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="../media/%s"/>`, j+1, media))
+		}
+		if err := add(fmt.Sprintf("xl/drawings/_rels/drawing%d.xml.rels", n), rels(drawingRels...)); err != nil {
+			return err
+		}
+		if err := add(fmt.Sprintf("xl/drawings/drawing%d.xml", n), drawingXML(s.images)); err != nil {
+			return err
+		}
+	}
+	types.WriteString(`</Types>`)
+
+	workbookRels.WriteString(fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(wb.sheets)+1))
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", types.String()},
+		{"_rels/.rels", rels(`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>`)},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>` + sheets.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", rels(workbookRels.String())},
+		{"xl/styles.xml", stylesXML},
+	}
+	for _, p := range parts {
+		if err := add(p.name, p.content); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+const stylesXML = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="3">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="22" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
+
+func rels(relationships ...string) string {
+	return xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		strings.Join(relationships, "") + `</Relationships>`
+}
+
+func sheetXML(s *Sheet) string {
+	var sb strings.Builder
+	sb.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheetData>`)
+
+	for i, r := range s.rows {
+		fmt.Fprintf(&sb, `<row r="%d">`, i+1)
+		for j, v := range r.values {
+			ref := cellRef(j, i)
+			style := styleDefault
+			if r.header {
+				style = styleHeader
+			}
+			writeCell(&sb, ref, style, v)
+		}
+		sb.WriteString(`</row>`)
+	}
+
+	sb.WriteString(`</sheetData>`)
+	if len(s.images) > 0 {
+		sb.WriteString(`<drawing r:id="rId1"/>`)
+	}
+	sb.WriteString(`</worksheet>`)
+	return sb.String()
+}
+
+func writeCell(sb *strings.Builder, ref string, style int, v any) {
+	number := func(f float64) {
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			fmt.Fprintf(sb, `<c r="%s" s="%d" t="inlineStr"><is><t></t></is></c>`, ref, style)
+			return
+		}
+		fmt.Fprintf(sb, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, strconv.FormatFloat(f, 'g', -1, 64))
+	}
+
+	switch x := v.(type) {
+	case nil:
+		return
+	case float64:
+		number(x)
+	case float32:
+		number(float64(x))
+	case int:
+		number(float64(x))
+	case int64:
+		number(float64(x))
+	case uint64:
+		number(float64(x))
+	case bool:
+		b := 0
+		if x {
+			b = 1
+		}
+		fmt.Fprintf(sb, `<c r="%s" s="%d" t="b"><v>%d</v></c>`, ref, style, b)
+	case time.Time:
+		fmt.Fprintf(sb, `<c r="%s" s="%d"><v>%s</v></c>`, ref, styleDate, strconv.FormatFloat(excelDate(x), 'f', 8, 64))
+	default:
+		fmt.Fprintf(sb, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, escape(fmt.Sprint(x)))
+	}
+}
+
+// days since 1899-12-30 in the value's own wall clock time
+func excelDate(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	return wall.Sub(epoch).Hours() / 24
+}
+
+func drawingXML(images []sheetImage) string {
+	const emuPerPixel = 9525
+
+	var sb strings.Builder
+	sb.WriteString(xml.Header + `<xdr:wsDr xmlns:xdr="http://schemas.openxmlformats.org/drawingml/2006/spreadsheetDrawing" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`)
+	for i, img := range images {
+		cx, cy := img.width*emuPerPixel, img.height*emuPerPixel
+		fmt.Fprintf(&sb, `<xdr:oneCellAnchor>`+
+			`<xdr:from><xdr:col>%d</xdr:col><xdr:colOff>0</xdr:colOff><xdr:row>%d</xdr:row><xdr:rowOff>0</xdr:rowOff></xdr:from>`+
+			`<xdr:ext cx="%d" cy="%d"/>`+
+			`<xdr:pic>`+
+			`<xdr:nvPicPr><xdr:cNvPr id="%d" name="Chart %d"/><xdr:cNvPicPr><a:picLocks noChangeAspect="1"/></xdr:cNvPicPr></xdr:nvPicPr>`+
+			`<xdr:blipFill><a:blip r:embed="rId%d"/><a:stretch><a:fillRect/></a:stretch></xdr:blipFill>`+
+			`<xdr:spPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="%d" cy="%d"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></xdr:spPr>`+
+			`</xdr:pic>`+
+			`<xdr:clientData/>`+
+			`</xdr:oneCellAnchor>`,
+			img.col, img.row, cx, cy, i+2, i+1, i+1, cx, cy)
+	}
+	sb.WriteString(`</xdr:wsDr>`)
+	return sb.String()
+}
+
+// cellRef converts zero based column and row numbers to A1 notation.
+func cellRef(col, row int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return name + strconv.Itoa(row+1)
+}
+
+func escape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
@@ -0,0 +1,209 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"heat-transfer/scenario"
+	"heat-transfer/scenario/scenariotest"
+	"image"
+	"image/png"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// unzip writes the workbook and returns its parts by name.
+func unzip(t *testing.T, wb *Workbook) map[string]string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := wb.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[f.Name] = string(b)
+	}
+	return parts
+}
+
+func TestCellRef(t *testing.T) {
+	tests := []struct {
+		col, row int
+		want     string
+	}{
+		{0, 0, "A1"}, {25, 1, "Z2"}, {26, 9, "AA10"}, {51, 0, "AZ1"}, {701, 0, "ZZ1"}, {702, 0, "AAA1"},
+	}
+	for _, tt := range tests {
+		if got := cellRef(tt.col, tt.row); got != tt.want {
+			t.Errorf("cellRef(%d, %d) = %s, want %s", tt.col, tt.row, got, tt.want)
+		}
+	}
+}
+
+func TestExcelDate(t *testing.T) {
+	tests := []struct {
+		t    time.Time
+		want float64
+	}{
+		{time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC), 2},
+		{time.Date(2024, 4, 15, 5, 0, 0, 0, time.UTC), 45397 + 5.0/24},
+		// the wall clock is kept, not converted to UTC
+		{time.Date(2024, 4, 15, 5, 0, 0, 0, time.FixedZone("ICT", 7*3600)), 45397 + 5.0/24},
+	}
+	for _, tt := range tests {
+		if got := excelDate(tt.t); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("excelDate(%v) = %v, want %v", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestAddSheetNames(t *testing.T) {
+	wb := NewWorkbook()
+	long := strings.Repeat("x", 40)
+	tests := []struct{ name, want string }{
+		{"Results", "Results"},
+		{"results", "results (2)"},
+		{"a/b:c", "a_b_c"},
+		{"", "Sheet"},
+		{long, long[:31]},
+		{long, long[:27] + " (2)"},
+	}
+	for _, tt := range tests {
+		if got := wb.AddSheet(tt.name).Name; got != tt.want {
+			t.Errorf("AddSheet(%q) named %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWriteCells(t *testing.T) {
+	wb := NewWorkbook()
+	s := wb.AddSheet("Data & notes")
+	s.AddHeader("Name", "Value")
+	s.AddRow("a < b", 1.5, 3, true, math.NaN(), nil, time.Date(2024, 4, 15, 5, 0, 0, 0, time.UTC))
+
+	parts := unzip(t, wb)
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("%s missing", name)
+		}
+	}
+	if !strings.Contains(parts["xl/workbook.xml"], `<sheet name="Data &amp; notes" sheetId="1" r:id="rId1"/>`) {
+		t.Errorf("sheet name not escaped in %s", parts["xl/workbook.xml"])
+	}
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	for _, cell := range []string{
+		`<c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">Name</t></is></c>`,
+		`<c r="A2" s="0" t="inlineStr"><is><t xml:space="preserve">a &lt; b</t></is></c>`,
+		`<c r="B2" s="0"><v>1.5</v></c>`,
+		`<c r="C2" s="0"><v>3</v></c>`,
+		`<c r="D2" s="0" t="b"><v>1</v></c>`,
+		`<c r="E2" s="0" t="inlineStr"><is><t></t></is></c>`,
+		`<c r="G2" s="2"><v>45397.20833333</v></c>`,
+	} {
+		if !strings.Contains(sheet, cell) {
+			t.Errorf("%s missing from %s", cell, sheet)
+		}
+	}
+	if strings.Contains(sheet, `r="F2"`) {
+		t.Error("a nil value should leave its cell out")
+	}
+	if strings.Contains(sheet, "<drawing") || strings.Contains(parts["[Content_Types].xml"], "drawing") {
+		t.Error("a sheet without images should have no drawing")
+	}
+}
+
+func TestWriteEmpty(t *testing.T) {
+	parts := unzip(t, NewWorkbook())
+	if !strings.Contains(parts["xl/workbook.xml"], `<sheet name="Sheet1"`) {
+		t.Errorf("an empty workbook should get a blank sheet, got %s", parts["xl/workbook.xml"])
+	}
+}
+
+func TestWriteImages(t *testing.T) {
+	var img bytes.Buffer
+	if err := png.Encode(&img, image.NewRGBA(image.Rect(0, 0, 4, 3))); err != nil {
+		t.Fatal(err)
+	}
+
+	wb := NewWorkbook()
+	wb.AddSheet("Plain").AddRow("no chart")
+	charts := wb.AddSheet("Charts")
+	for _, col := range []int{0, 5} {
+		if err := charts.AddImage(img.Bytes(), col, 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := charts.AddImage([]byte("not a png"), 0, 0); err == nil {
+		t.Error("an undecodable image should be refused")
+	}
+
+	parts := unzip(t, wb)
+	for _, name := range []string{"xl/media/image1.png", "xl/media/image2.png", "xl/drawings/drawing2.xml", "xl/drawings/_rels/drawing2.xml.rels", "xl/worksheets/_rels/sheet2.xml.rels"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("%s missing", name)
+		}
+	}
+	if parts["xl/media/image1.png"] != img.String() {
+		t.Error("image bytes changed")
+	}
+	if _, ok := parts["xl/drawings/drawing1.xml"]; ok {
+		t.Error("the sheet without images got a drawing")
+	}
+	if !strings.Contains(parts["xl/worksheets/sheet2.xml"], `<drawing r:id="rId1"/>`) {
+		t.Error("the sheet does not reference its drawing")
+	}
+	drawing := parts["xl/drawings/drawing2.xml"]
+	if n := strings.Count(drawing, "<xdr:oneCellAnchor>"); n != 2 {
+		t.Errorf("%d anchors, want 2", n)
+	}
+	// 4 by 3 px at 9525 EMU per pixel
+	if !strings.Contains(drawing, `<xdr:ext cx="38100" cy="28575"/>`) || !strings.Contains(drawing, `<xdr:col>5</xdr:col>`) {
+		t.Errorf("image size or position wrong in %s", drawing)
+	}
+}
+
+func TestScenarioWorkbook(t *testing.T) {
+	s, outside := scenariotest.Room()
+	r, err := scenario.Run(s, outside)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wb, err := ScenarioWorkbook(r, time.Date(2024, 4, 15, 5, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parts := unzip(t, wb)
+	for _, name := range []string{`"Inputs"`, `"Time series"`, `"Cost summary"`} {
+		if !strings.Contains(parts["xl/workbook.xml"], "<sheet name="+name) {
+			t.Errorf("sheet %s missing", name)
+		}
+	}
+	series := parts["xl/worksheets/sheet2.xml"]
+	if n := strings.Count(series, "<row "); n != 841 {
+		t.Errorf("%d time series rows, want a header and 840 minutes", n)
+	}
+	// the first minute is 05:00 on the simulated day, the last 18:59
+	if !strings.Contains(series, `<c r="A2" s="2"><v>45397.20833333</v></c>`) || !strings.Contains(series, `<c r="A841" s="2"><v>45397.79097222</v></c>`) {
+		t.Error("timestamps do not run from 05:00 to 18:59 on the simulated day")
+	}
+	if _, ok := parts["xl/media/image1.png"]; !ok {
+		t.Error("temperature chart missing")
+	}
+}
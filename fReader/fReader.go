@@ -1,6 +1,9 @@
 package freader
 
-import "os"
+import (
+	"os"
+	"strings"
+)
 
 func GetToken() string {
 	buf, err := os.ReadFile("./token")
@@ -9,4 +12,14 @@ func GetToken() string {
 	}
 
 	return string(buf)
-}
\ No newline at end of file
+}
+
+// ReadToken reads an API token file without panicking when it is missing.
+func ReadToken(path string) (string, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(buf)), nil
+}
@@ -0,0 +1,235 @@
+package finance
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Scenario is the cost side of one design option, amounts in THB.
+type Scenario struct {
+	Name              string  `json:"name"`
+	InitialCost       float64 `json:"initial_cost"`       // up-front material and installation
+	AnnualEnergyCost  float64 `json:"annual_energy_cost"` // first-year electricity cost
+	AnnualMaintenance float64 `json:"annual_maintenance"` // first-year maintenance cost
+}
+
+type Assumptions struct {
+	Years                 int     `json:"years"`
+	DiscountRate          float64 `json:"discount_rate"`          // 0.05 = 5% per year
+	EnergyEscalation      float64 `json:"energy_escalation"`      // yearly electricity price increase
+	MaintenanceEscalation float64 `json:"maintenance_escalation"` // yearly maintenance cost increase
+}
+
+func DefaultAssumptions() Assumptions {
+	return Assumptions{
+		Years:                 20,
+		DiscountRate:          0.05,
+		EnergyEscalation:      0.03,
+		MaintenanceEscalation: 0.02,
+	}
+}
+
+type YearRow struct {
+	Year                 int     `json:"year"`
+	BaselineCost         float64 `json:"baseline_cost"`
+	UpgradeCost          float64 `json:"upgrade_cost"`
+	CashFlow             float64 `json:"cash_flow"` // upgrade saving over baseline
+	DiscountedCashFlow   float64 `json:"discounted_cash_flow"`
+	Cumulative           float64 `json:"cumulative"`
+	DiscountedCumulative float64 `json:"discounted_cumulative"`
+}
+
+// Report compares an upgrade against a baseline. Paybacks are in years and
+// are +Inf when the upgrade never pays back; IRR is NaN when undefined.
+type Report struct {
+	Baseline          Scenario    `json:"baseline"`
+	Upgrade           Scenario    `json:"upgrade"`
+	Assumptions       Assumptions `json:"assumptions"`
+	Rows              []YearRow   `json:"rows"`
+	ExtraInvestment   float64     `json:"extra_investment"`
+	NPV               float64     `json:"npv"`
+	SimplePayback     float64     `json:"simple_payback"`
+	DiscountedPayback float64     `json:"discounted_payback"`
+	IRR               float64     `json:"irr"`
+}
+
+// Analyze computes the life-cycle cash flows of upgrade relative to baseline.
+func Analyze(baseline, upgrade Scenario, a Assumptions) (Report, error) {
+	if a.Years <= 0 {
+		return Report{}, errors.New("analysis period must be at least one year")
+	}
+	if a.DiscountRate <= -1 {
+		return Report{}, errors.New("discount rate must be greater than -100%")
+	}
+
+	r := Report{
+		Baseline:        baseline,
+		Upgrade:         upgrade,
+		Assumptions:     a,
+		ExtraInvestment: upgrade.InitialCost - baseline.InitialCost,
+	}
+
+	cashflows := make([]float64, a.Years+1)
+	cashflows[0] = -r.ExtraInvestment
+	r.Rows = append(r.Rows, YearRow{
+		Year:                 0,
+		BaselineCost:         baseline.InitialCost,
+		UpgradeCost:          upgrade.InitialCost,
+		CashFlow:             cashflows[0],
+		DiscountedCashFlow:   cashflows[0],
+		Cumulative:           cashflows[0],
+		DiscountedCumulative: cashflows[0],
+	})
+
+	for year := 1; year <= a.Years; year++ {
+		energy := math.Pow(1+a.EnergyEscalation, float64(year-1))
+		maintenance := math.Pow(1+a.MaintenanceEscalation, float64(year-1))
+
+		baseCost := baseline.AnnualEnergyCost*energy + baseline.AnnualMaintenance*maintenance
+		upCost := upgrade.AnnualEnergyCost*energy + upgrade.AnnualMaintenance*maintenance
+
+		cashflows[year] = baseCost - upCost
+		discounted := cashflows[year] / math.Pow(1+a.DiscountRate, float64(year))
+
+		prev := r.Rows[year-1]
+		r.Rows = append(r.Rows, YearRow{
+			Year:                 year,
+			BaselineCost:         baseCost,
+			UpgradeCost:          upCost,
+			CashFlow:             cashflows[year],
+			DiscountedCashFlow:   discounted,
+			Cumulative:           prev.Cumulative + cashflows[year],
+			DiscountedCumulative: prev.DiscountedCumulative + discounted,
+		})
+	}
+
+	r.NPV = NPV(a.DiscountRate, cashflows)
+	r.SimplePayback = payback(r.Rows, func(row YearRow) float64 { return row.Cumulative })
+	r.DiscountedPayback = payback(r.Rows, func(row YearRow) float64 { return row.DiscountedCumulative })
+
+	irr, err := IRR(cashflows)
+	if err != nil {
+		irr = math.NaN()
+	}
+	r.IRR = irr
+
+	return r, nil
+}
+
+// NPV discounts cashflows[t] occurring at the end of year t.
+func NPV(rate float64, cashflows []float64) float64 {
+	npv := 0.0
+	for t, cf := range cashflows {
+		npv += cf / math.Pow(1+rate, float64(t))
+	}
+	return npv
+}
+
+// IRR finds the discount rate at which NPV is zero by bisection.
+func IRR(cashflows []float64) (float64, error) {
+	lo, hi := -0.99, 10.0
+	fLo, fHi := NPV(lo, cashflows), NPV(hi, cashflows)
+	if math.IsNaN(fLo) || math.IsNaN(fHi) || fLo*fHi > 0 {
+		return 0, errors.New("IRR is undefined for these cash flows")
+	}
+
+	for range 200 {
+		mid := (lo + hi) / 2
+		fMid := NPV(mid, cashflows)
+		if math.Abs(fMid) < 1e-9 || hi-lo < 1e-12 {
+			return mid, nil
+		}
+		if fLo*fMid < 0 {
+			hi = mid
+		} else {
+			lo, fLo = mid, fMid
+		}
+	}
+	return (lo + hi) / 2, nil
+}
+
+// first year the cumulative value turns non-negative, interpolated within the year
+func payback(rows []YearRow, cumulative func(YearRow) float64) float64 {
+	if cumulative(rows[0]) >= 0 {
+		return 0
+	}
+	for i := 1; i < len(rows); i++ {
+		prev, curr := cumulative(rows[i-1]), cumulative(rows[i])
+		if curr >= 0 {
+			return float64(i-1) + -prev/(curr-prev)
+		}
+	}
+	return math.Inf(1)
+}
+
+// MarshalJSON reports a missing payback or IRR as null, since JSON has no Inf or NaN.
+func (r Report) MarshalJSON() ([]byte, error) {
+	finite := func(v float64) *float64 {
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return nil
+		}
+		return &v
+	}
+
+	type report Report
+	return json.Marshal(struct {
+		report
+		SimplePayback     *float64 `json:"simple_payback"`
+		DiscountedPayback *float64 `json:"discounted_payback"`
+		IRR               *float64 `json:"irr"`
+	}{
+		report:            report(r),
+		SimplePayback:     finite(r.SimplePayback),
+		DiscountedPayback: finite(r.DiscountedPayback),
+		IRR:               finite(r.IRR),
+	})
+}
+
+func (r Report) String() string {
+	return fmt.Sprintf(
+		"%s vs %s over %d years: extra investment %.2f THB, NPV %.2f THB, simple payback %s, discounted payback %s, IRR %s",
+		r.Upgrade.Name, r.Baseline.Name, r.Assumptions.Years, r.ExtraInvestment, r.NPV,
+		FormatYears(r.SimplePayback), FormatYears(r.DiscountedPayback), FormatPercent(r.IRR),
+	)
+}
+
+func FormatYears(v float64) string {
+	if math.IsInf(v, 1) {
+		return "never"
+	}
+	return fmt.Sprintf("%.1f years", v)
+}
+
+func FormatPercent(v float64) string {
+	if math.IsNaN(v) {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.1f%%", v*100)
+}
+
+// WriteCSV writes the year-by-year cash flow table.
+func (r Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	c := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+
+	if err := cw.Write([]string{"year", "baseline_cost", "upgrade_cost", "cash_flow", "discounted_cash_flow", "cumulative", "discounted_cumulative"}); err != nil {
+		return err
+	}
+	for _, row := range r.Rows {
+		rec := []string{
+			strconv.Itoa(row.Year), c(row.BaselineCost), c(row.UpgradeCost), c(row.CashFlow),
+			c(row.DiscountedCashFlow), c(row.Cumulative), c(row.DiscountedCumulative),
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,152 @@
+package finance
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func near(a, b, tol float64) bool { return math.Abs(a-b) <= tol }
+
+func TestNPV(t *testing.T) {
+	tests := []struct {
+		name      string
+		rate      float64
+		cashflows []float64
+		want      float64
+	}{
+		{"undiscounted", 0, []float64{-100, 50, 60}, 10},
+		{"breaks even at its IRR", 0.1, []float64{-100, 110}, 0},
+		{"uneven", 0.05, []float64{-1000, 300, 400, 500}, 80.44487636},
+		{"year 0 only", 0.2, []float64{-500}, -500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NPV(tt.rate, tt.cashflows); !near(got, tt.want, 1e-6) {
+				t.Errorf("NPV %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIRR(t *testing.T) {
+	tests := []struct {
+		name      string
+		cashflows []float64
+		want      float64
+	}{
+		{"one year", []float64{-100, 110}, 0.10},
+		{"two years", []float64{-100, 0, 121}, 0.10},
+		{"annuity", []float64{-1000, 500, 500, 500}, 0.2337519285},
+		{"ten year saving", []float64{-20000, 4000, 4000, 4000, 4000, 4000, 4000, 4000, 4000, 4000, 4000}, 0.1509841456},
+		{"loss", []float64{-100, 50, 40}, -0.0699264746},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IRR(tt.cashflows)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !near(got, tt.want, 1e-4) {
+				t.Errorf("IRR %v, want %v", got, tt.want)
+			}
+			if npv := NPV(got, tt.cashflows); !near(npv, 0, 1e-6) {
+				t.Errorf("NPV at the IRR is %v, want 0", npv)
+			}
+		})
+	}
+
+	for _, cf := range [][]float64{{100, 10}, {-100, -10}} {
+		if _, err := IRR(cf); err == nil {
+			t.Errorf("IRR of %v should be undefined", cf)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	baseline := Scenario{Name: "Base", InitialCost: 10000, AnnualEnergyCost: 10000}
+	upgrade := Scenario{Name: "Insulated", InitialCost: 30000, AnnualEnergyCost: 6000}
+
+	tests := []struct {
+		name       string
+		a          Assumptions
+		npv        float64
+		simple     float64
+		discounted float64
+		irr        float64
+	}{
+		{"undiscounted", Assumptions{Years: 10}, 20000, 5, 5, 0.1509841456},
+		{"discounted", Assumptions{Years: 10, DiscountRate: 0.05}, 10886.93971674, 5, 5.89856539, 0.1509841456},
+		{"never pays back", Assumptions{Years: 4, DiscountRate: 0.05}, -5816.19798335, math.Inf(1), math.Inf(1), -0.0836454175},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Analyze(baseline, upgrade, tt.a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.ExtraInvestment != 20000 || len(r.Rows) != tt.a.Years+1 {
+				t.Fatalf("extra investment %v over %d rows", r.ExtraInvestment, len(r.Rows))
+			}
+			if !near(r.NPV, tt.npv, 1e-4) {
+				t.Errorf("NPV %v, want %v", r.NPV, tt.npv)
+			}
+			if r.SimplePayback != tt.simple && !near(r.SimplePayback, tt.simple, 1e-6) {
+				t.Errorf("simple payback %v, want %v", r.SimplePayback, tt.simple)
+			}
+			if r.DiscountedPayback != tt.discounted && !near(r.DiscountedPayback, tt.discounted, 1e-6) {
+				t.Errorf("discounted payback %v, want %v", r.DiscountedPayback, tt.discounted)
+			}
+			if !near(r.IRR, tt.irr, 1e-4) {
+				t.Errorf("IRR %v, want %v", r.IRR, tt.irr)
+			}
+			last := r.Rows[len(r.Rows)-1]
+			if !near(last.DiscountedCumulative, r.NPV, 1e-6) {
+				t.Errorf("discounted cumulative %v does not end at the NPV %v", last.DiscountedCumulative, r.NPV)
+			}
+		})
+	}
+}
+
+func TestAnalyzeEscalation(t *testing.T) {
+	baseline := Scenario{AnnualEnergyCost: 10000, AnnualMaintenance: 1000}
+	upgrade := Scenario{InitialCost: 5000}
+	r, err := Analyze(baseline, upgrade, Assumptions{Years: 3, EnergyEscalation: 0.03, MaintenanceEscalation: 0.1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the first year is at today's prices
+	for i, want := range []float64{11000, 10300 + 1100, 10609 + 1210} {
+		if got := r.Rows[i+1].BaselineCost; !near(got, want, 1e-6) {
+			t.Errorf("year %d baseline cost %v, want %v", i+1, got, want)
+		}
+	}
+	if r.SimplePayback >= 1 {
+		t.Errorf("payback %v, want within the first year", r.SimplePayback)
+	}
+}
+
+func TestAnalyzeInvalid(t *testing.T) {
+	for _, a := range []Assumptions{{Years: 0}, {Years: 5, DiscountRate: -1}} {
+		if _, err := Analyze(Scenario{}, Scenario{}, a); err == nil {
+			t.Errorf("assumptions %+v should be refused", a)
+		}
+	}
+}
+
+func TestReportJSON(t *testing.T) {
+	r, err := Analyze(Scenario{AnnualEnergyCost: 100}, Scenario{InitialCost: 10000, AnnualEnergyCost: 90}, Assumptions{Years: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{`"simple_payback":null`, `"discounted_payback":null`} {
+		if !strings.Contains(string(buf), field) {
+			t.Errorf("%s missing from %s", field, buf)
+		}
+	}
+}
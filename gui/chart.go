@@ -0,0 +1,371 @@
+package gui
+
+import (
+	"fmt"
+	"heat-transfer/chartings"
+	"heat-transfer/scenario"
+	"image"
+	"image/color"
+	"math"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+)
+
+// shortest time range, in minutes, the chart can be zoomed into
+const minZoomSpan = 15.0
+
+// temperatureChart draws a simulation result and lets the user hover for
+// values, drag to zoom into a time range, scroll to zoom around the pointer
+// and double click to reset. The plot is only re-rendered when the result,
+// the zoom or the size changes; the cursor and hover marks are overlays.
+type temperatureChart struct {
+	widget.BaseWidget
+
+	// OnTapped is called with the minute that was clicked.
+	OnTapped func(minute float64)
+
+	mu               sync.Mutex
+	result           scenario.Result
+	viewMin, viewMax float64 // visible minutes
+	cursor           float64 // minute, hidden when negative
+	hover            float64 // minute, hidden when negative
+	dragFrom, dragTo float64 // zoom selection, hidden when dragFrom is negative
+
+	// data area of the last render as fractions of the widget size, top left
+	// origin
+	left, right, top, bottom float32
+}
+
+func newTemperatureChart() *temperatureChart {
+	c := &temperatureChart{cursor: -1, hover: -1, dragFrom: -1}
+	c.ExtendBaseWidget(c)
+	return c
+}
+
+// SetResult shows r and resets the zoom.
+func (c *temperatureChart) SetResult(r scenario.Result) {
+	c.mu.Lock()
+	c.result = r
+	c.viewMin, c.viewMax = 0, c.fullSpan()
+	c.mu.Unlock()
+	c.Refresh()
+}
+
+// SetCursor draws the time cursor at minute.
+func (c *temperatureChart) SetCursor(minute float64) {
+	c.mu.Lock()
+	c.cursor = minute
+	c.mu.Unlock()
+	c.Refresh()
+}
+
+func (c *temperatureChart) ResetZoom() {
+	c.mu.Lock()
+	c.viewMin, c.viewMax = 0, c.fullSpan()
+	c.mu.Unlock()
+	c.Refresh()
+}
+
+func (c *temperatureChart) fullSpan() float64 {
+	if n := len(c.result.TimeMinutes); n > 0 {
+		return c.result.TimeMinutes[n-1]
+	}
+	return 0
+}
+
+// zoom sets the visible range, clamped to the simulated day.
+func (c *temperatureChart) zoom(from, to float64) {
+	if from > to {
+		from, to = to, from
+	}
+	full := c.fullSpan()
+	if to-from < minZoomSpan {
+		mid := (from + to) / 2
+		from, to = mid-minZoomSpan/2, mid+minZoomSpan/2
+	}
+	if from < 0 {
+		from, to = 0, to-from
+	}
+	if to > full {
+		from, to = math.Max(0, from-(to-full)), full
+	}
+	c.viewMin, c.viewMax = from, to
+}
+
+// minuteAt converts a widget x position to a minute, false outside the
+// data area.
+func (c *temperatureChart) minuteAt(x float32) (float64, bool) {
+	width := c.Size().Width
+	if width <= 0 || c.right <= c.left {
+		return 0, false
+	}
+	f := (x/width - c.left) / (c.right - c.left)
+	if f < 0 || f > 1 {
+		return 0, false
+	}
+	return c.viewMin + float64(f)*(c.viewMax-c.viewMin), true
+}
+
+// xAt converts a minute to a widget x position.
+func (c *temperatureChart) xAt(minute float64) float32 {
+	f := float32((minute - c.viewMin) / (c.viewMax - c.viewMin))
+	return (c.left + f*(c.right-c.left)) * c.Size().Width
+}
+
+// sample returns the index of the recorded minute closest to minute.
+func (c *temperatureChart) sample(minute float64) int {
+	n := len(c.result.InsideTemps)
+	return min(max(int(math.Round(minute)), 0), n-1)
+}
+
+func (c *temperatureChart) MouseIn(ev *desktop.MouseEvent) { c.MouseMoved(ev) }
+
+func (c *temperatureChart) MouseMoved(ev *desktop.MouseEvent) {
+	c.mu.Lock()
+	minute, ok := c.minuteAt(ev.Position.X)
+	if !ok {
+		minute = -1
+	}
+	c.hover = minute
+	c.mu.Unlock()
+	c.Refresh()
+}
+
+func (c *temperatureChart) MouseOut() {
+	c.mu.Lock()
+	c.hover = -1
+	c.mu.Unlock()
+	c.Refresh()
+}
+
+func (c *temperatureChart) Scrolled(ev *fyne.ScrollEvent) {
+	c.mu.Lock()
+	center, ok := c.minuteAt(ev.Position.X)
+	if ok && len(c.result.TimeMinutes) > 0 {
+		factor := 1.25
+		if ev.Scrolled.DY > 0 {
+			factor = 0.8
+		}
+		c.zoom(center-(center-c.viewMin)*factor, center+(c.viewMax-center)*factor)
+	}
+	c.mu.Unlock()
+	c.Refresh()
+}
+
+func (c *temperatureChart) Dragged(ev *fyne.DragEvent) {
+	c.mu.Lock()
+	if c.dragFrom < 0 {
+		if from, ok := c.minuteAt(ev.Position.X - ev.Dragged.DX); ok {
+			c.dragFrom = from
+		}
+	}
+	if to, ok := c.minuteAt(ev.Position.X); ok {
+		c.dragTo = to
+	}
+	c.mu.Unlock()
+	c.Refresh()
+}
+
+func (c *temperatureChart) DragEnd() {
+	c.mu.Lock()
+	if c.dragFrom >= 0 && math.Abs(c.dragTo-c.dragFrom) >= 1 {
+		c.zoom(c.dragFrom, c.dragTo)
+	}
+	c.dragFrom = -1
+	c.mu.Unlock()
+	c.Refresh()
+}
+
+func (c *temperatureChart) Tapped(ev *fyne.PointEvent) {
+	c.mu.Lock()
+	minute, ok := c.minuteAt(ev.Position.X)
+	c.mu.Unlock()
+	if ok && c.OnTapped != nil {
+		c.OnTapped(minute)
+	}
+}
+
+func (c *temperatureChart) DoubleTapped(*fyne.PointEvent) {
+	c.ResetZoom()
+}
+
+// render draws the visible range of the result into an image of w x h
+// pixels and records where the data area ended up.
+func (c *temperatureChart) render(w, h int) image.Image {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	r := c.result
+	if len(r.InsideTemps) == 0 || w <= 0 || h <= 0 {
+		return img
+	}
+
+	o := chartings.DefaultOptions()
+	o.Title = ""
+	if ac := r.Scenario.AC; ac != nil && ac.Enabled {
+		o.SetPoint = &ac.SetTemp
+		o.AC = &chartings.ACOverlay{Running: r.ACRunning, OnTime: ac.OnTime, OffTime: ac.OffTime, CoolingPower: ac.CoolingPower}
+	}
+	p, err := chartings.TemperaturePlot(
+		chartings.XYs(r.TimeMinutes, r.InsideTemps),
+		chartings.XYs(r.TimeMinutes, r.OutsideTemps), o)
+	if err != nil {
+		fmt.Println(err)
+		return img
+	}
+	p.X.Min, p.X.Max = c.viewMin, c.viewMax
+
+	// keep text readable on high density screens
+	dpi := 96.0
+	if size := c.Size(); size.Width > 0 {
+		dpi *= float64(w) / float64(size.Width)
+	}
+	vc := vgimg.NewWith(vgimg.UseImage(img), vgimg.UseDPI(int(dpi)))
+	dc := draw.New(vc)
+	data := p.DataCanvas(dc)
+	p.Draw(dc)
+
+	width, height := dc.Max.X-dc.Min.X, dc.Max.Y-dc.Min.Y
+	left := float32((data.Min.X - dc.Min.X) / width)
+	right := float32((data.Max.X - dc.Min.X) / width)
+	top := float32(1 - (data.Max.Y-dc.Min.Y)/height)
+	bottom := float32(1 - (data.Min.Y-dc.Min.Y)/height)
+	if left != c.left || right != c.right || top != c.top || bottom != c.bottom {
+		c.left, c.right, c.top, c.bottom = left, right, top, bottom
+		// the overlays are placed relative to the data area
+		go c.Refresh()
+	}
+
+	return vc.Image()
+}
+
+func (c *temperatureChart) CreateRenderer() fyne.WidgetRenderer {
+	r := &temperatureChartRenderer{
+		chart:     c,
+		cursor:    canvas.NewLine(theme.Color(theme.ColorNamePrimary)),
+		hover:     canvas.NewLine(theme.Color(theme.ColorNameForeground)),
+		selection: canvas.NewRectangle(color.NRGBA{R: 80, G: 160, B: 230, A: 60}),
+		tipBack:   canvas.NewRectangle(theme.Color(theme.ColorNameOverlayBackground)),
+		tip:       canvas.NewText("", theme.Color(theme.ColorNameForeground)),
+	}
+	r.raster = canvas.NewRaster(c.render)
+	r.cursor.StrokeWidth = 2
+	r.hover.StrokeWidth = 1
+	r.tipBack.StrokeColor = theme.Color(theme.ColorNameShadow)
+	r.tipBack.StrokeWidth = 1
+	r.tip.TextSize = theme.TextSize() * 0.9
+	return r
+}
+
+type temperatureChartRenderer struct {
+	chart     *temperatureChart
+	raster    *canvas.Raster
+	cursor    *canvas.Line
+	hover     *canvas.Line
+	selection *canvas.Rectangle
+	tipBack   *canvas.Rectangle
+	tip       *canvas.Text
+
+	// state of the last raster refresh
+	rendered                 scenario.Result
+	renderedMin, renderedMax float64
+}
+
+func (r *temperatureChartRenderer) Layout(size fyne.Size) {
+	r.raster.Resize(size)
+	r.raster.Move(fyne.NewPos(0, 0))
+	r.updateOverlay(size)
+}
+
+func (r *temperatureChartRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(600, 300)
+}
+
+func (r *temperatureChartRenderer) Refresh() {
+	c := r.chart
+	c.mu.Lock()
+	stale := len(r.rendered.InsideTemps) != len(c.result.InsideTemps) ||
+		(len(c.result.InsideTemps) > 0 && &r.rendered.InsideTemps[0] != &c.result.InsideTemps[0]) ||
+		r.renderedMin != c.viewMin || r.renderedMax != c.viewMax
+	r.rendered, r.renderedMin, r.renderedMax = c.result, c.viewMin, c.viewMax
+	c.mu.Unlock()
+
+	if stale {
+		r.raster.Refresh()
+	}
+	r.updateOverlay(c.Size())
+}
+
+// updateOverlay positions the cursor, hover line, zoom selection and tooltip.
+func (r *temperatureChartRenderer) updateOverlay(size fyne.Size) {
+	c := r.chart
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	top, bottom := c.top*size.Height, c.bottom*size.Height
+	visible := func(minute float64) bool {
+		return minute >= c.viewMin && minute <= c.viewMax && c.viewMax > c.viewMin && len(c.result.InsideTemps) > 0
+	}
+	placeLine := func(l *canvas.Line, minute float64) {
+		if !visible(minute) {
+			l.Hide()
+			return
+		}
+		x := c.xAt(minute)
+		l.Position1 = fyne.NewPos(x, top)
+		l.Position2 = fyne.NewPos(x, bottom)
+		l.Show()
+		l.Refresh()
+	}
+	placeLine(r.cursor, c.cursor)
+	placeLine(r.hover, c.hover)
+
+	if c.dragFrom >= 0 {
+		x0, x1 := c.xAt(c.dragFrom), c.xAt(c.dragTo)
+		r.selection.Move(fyne.NewPos(min(x0, x1), top))
+		r.selection.Resize(fyne.NewSize(float32(math.Abs(float64(x1-x0))), bottom-top))
+		r.selection.Show()
+	} else {
+		r.selection.Hide()
+	}
+
+	if !visible(c.hover) {
+		r.tip.Hide()
+		r.tipBack.Hide()
+		return
+	}
+	i := c.sample(c.hover)
+	ac := "AC off"
+	if c.result.Cooling(i) {
+		ac = "AC on"
+	}
+	r.tip.Text = fmt.Sprintf("%s   in %.1f °C   out %.1f °C   %s",
+		convertTime(c.result.TimeMinutes[i]), c.result.InsideTemps[i], c.result.OutsideTemps[i], ac)
+	r.tip.Refresh()
+
+	pad := theme.InnerPadding() / 2
+	tipSize := r.tip.MinSize()
+	x := c.xAt(c.hover) + pad
+	if x+tipSize.Width+2*pad > size.Width {
+		x = c.xAt(c.hover) - tipSize.Width - 3*pad
+	}
+	r.tipBack.Move(fyne.NewPos(x, top))
+	r.tipBack.Resize(fyne.NewSize(tipSize.Width+2*pad, tipSize.Height+2*pad))
+	r.tip.Move(fyne.NewPos(x+pad, top+pad))
+	r.tip.Show()
+	r.tipBack.Show()
+}
+
+func (r *temperatureChartRenderer) Objects() []fyne.CanvasObject {
+	return []fyne.CanvasObject{r.raster, r.selection, r.cursor, r.hover, r.tipBack, r.tip}
+}
+
+func (r *temperatureChartRenderer) Destroy() {}
@@ -0,0 +1,58 @@
+package gui
+
+import (
+	"errors"
+	"fmt"
+	"heat-transfer/constants"
+	"os"
+
+	"fyne.io/fyne/v2"
+)
+
+const ratesFile = "./rates.json"
+
+// currency used for every cost shown in the window
+var displayCurrency = constants.BaseCurrency
+
+func loadExchangeRates() {
+	if err := constants.LoadExchangeRates(ratesFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		fmt.Println(err)
+	}
+}
+
+func currencyMenu(onChanged func()) *fyne.Menu {
+	menu := fyne.NewMenu("Currency")
+	for _, c := range constants.Currencies() {
+		item := fyne.NewMenuItem(c, nil)
+		item.Checked = c == displayCurrency
+		item.Action = func() {
+			displayCurrency = c
+			for _, other := range menu.Items {
+				other.Checked = other.Label == c
+			}
+			menu.Refresh()
+			onChanged()
+		}
+		menu.Items = append(menu.Items, item)
+	}
+	return menu
+}
+
+func formatCost(thb float64) string {
+	v, err := constants.Convert(thb, constants.BaseCurrency, displayCurrency)
+	if err != nil {
+		return fmt.Sprintf("%.2f %s", thb, constants.BaseCurrency)
+	}
+	return fmt.Sprintf("%.2f %s", v, displayCurrency)
+}
+
+func formatCostRange(p constants.PriceRange) string {
+	if p.Currency == "" {
+		p.Currency = constants.BaseCurrency
+	}
+	converted, err := p.Convert(displayCurrency)
+	if err != nil {
+		return p.String()
+	}
+	return converted.String()
+}
@@ -0,0 +1,87 @@
+package gui
+
+import (
+	"errors"
+	"heat-transfer/calc"
+	"heat-transfer/export"
+	"heat-transfer/report"
+	"heat-transfer/scenario"
+	"io"
+	"path/filepath"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+)
+
+// result of the last Calculate, used by every export
+var lastResult scenario.Result
+var lastResultStart time.Time
+
+// simulatedDay is 05:00 on the day the weather is for, today for weather
+// without a date.
+func simulatedDay(w calc.Weather) time.Time {
+	if !w.Start.IsZero() {
+		return w.Start
+	}
+	return export.DayStart(time.Now())
+}
+
+func fileMenu(w fyne.Window) *fyne.Menu {
+	return fyne.NewMenu("File",
+		fyne.NewMenuItem("Export CSV...", func() {
+			saveExport(w, "timeseries.csv", func(wr io.Writer) error {
+				return export.FromResult(lastResult, lastResultStart).WriteCSV(wr)
+			})
+		}),
+		fyne.NewMenuItem("Export JSON...", func() {
+			saveExport(w, "timeseries.json", func(wr io.Writer) error {
+				return export.FromResult(lastResult, lastResultStart).WriteJSON(wr)
+			})
+		}),
+		fyne.NewMenuItem("Export Excel...", func() {
+			saveExport(w, "results.xlsx", func(wr io.Writer) error {
+				wb, err := export.ScenarioWorkbook(lastResult, lastResultStart)
+				if err != nil {
+					return err
+				}
+				return wb.Write(wr)
+			})
+		}),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Export PDF report...", func() {
+			saveExport(w, "report.pdf", func(wr io.Writer) error {
+				return report.Write(wr, report.ProjectInfo{Date: lastResultStart}, lastResult)
+			})
+		}),
+	)
+}
+
+func saveExport(w fyne.Window, name string, write func(io.Writer) error) {
+	if len(lastResult.InsideTemps) == 0 {
+		dialog.ShowError(errors.New("nothing to export, press Calculate first"), w)
+		return
+	}
+	saveFile(w, name, write)
+}
+
+func saveFile(w fyne.Window, name string, write func(io.Writer) error) {
+	d := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if wc == nil {
+			return
+		}
+		defer wc.Close()
+
+		if err := write(wc); err != nil {
+			dialog.ShowError(err, w)
+		}
+	}, w)
+	d.SetFileName(name)
+	d.SetFilter(storage.NewExtensionFileFilter([]string{filepath.Ext(name)}))
+	d.Show()
+}
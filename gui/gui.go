@@ -1,13 +1,10 @@
 package gui
 
 import (
-	"bytes"
 	"fmt"
 	"heat-transfer/calc"
-	"heat-transfer/chartings"
 	"heat-transfer/constants"
-	freader "heat-transfer/fReader"
-	weatherdata "heat-transfer/weatherData"
+	"heat-transfer/scenario"
 	"math"
 	"os"
 	"strconv"
@@ -15,18 +12,17 @@ import (
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
-	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
-	"gonum.org/v1/plot/plotter"
 )
 
 var customMaterialInput *widget.Entry
 var calculateButton *widget.Button
 
 var currLocation, material string
-var totalCost float64
-var ACprofile []bool
+var totalCost constants.PriceRange
+var billOfQuantities calc.BillOfQuantities
+var monthlyACCostTHB float64
 
 type Result struct {
 	Time    string
@@ -83,18 +79,25 @@ func StartGUILoop() {
 		ACCoolingPower: -3000.0,
 	}
 
-	temperature, err := weatherdata.GetCityTemperatureForecastNow("Khon+Kaen,+TH", freader.GetToken())
+	weatherProvider = defaultProvider()
+	weather, err := weatherProvider.Forecast("Khon+Kaen,+TH")
 	if err != nil {
 		fmt.Println(err)
 	}
+	temperature := weather.Temp
 
 	resultsForDay := Results{
 		InTemp:  [840]float64(temperature),
 		OutTemp: [840]float64(temperature),
 	}
 
+	loadMaterialFiles()
+	loadExchangeRates()
+
 	a := app.New()
 	w := a.NewWindow("Heat Transfer Coefficient Calculator")
+	showQualityReports(w)
+	showCacheReports(w)
 
 	w.SetOnClosed(func() {
 		a.Quit()
@@ -104,36 +107,20 @@ func StartGUILoop() {
 	w.SetFixedSize(true)
 	w.Resize(fyne.NewSize(650, 700))
 
-	imageElem := canvas.NewImageFromReader(calculate(params.W, params.H, params.L, params.InsideTemp, [840]float64(temperature), params.Coeff), "chart.png")
-	imageElem.FillMode = canvas.ImageFillOriginal
+	chart := newTemperatureChart()
+	if res, err := scenario.Run(scenario.Scenario{Name: "Current", Width: params.W, Height: params.H, Depth: params.L, Coeff: params.Coeff}, temperature); err == nil {
+		chart.SetResult(res)
+	}
 
 	// row 1
 	label := widget.NewLabel("Material type")
 
 	// material selector
-	materialTypeSelector := widget.NewSelect([]string{"Wood", "Lightweight bricks", "Concrete", "Fibre glass", "PS foam", "PE foam", "Custom"}, selectHandler)
+	materialTypeSelector := widget.NewSelect(materialOptions(), selectHandler)
 	materialTypeSelector.Resize(fyne.Size{Width: 150, Height: 10})
 	materialTypeSelector.OnChanged = func(s string) {
-		switch s {
-		case "Wood":
-			params.Coeff = constants.ThermalConductivity["wood"]
-			material = "wood"
-		case "Lightweight bricks":
-			params.Coeff = constants.ThermalConductivity["brick"]
-			material = "brick"
-		case "Concrete":
-			params.Coeff = constants.ThermalConductivity["concrete"]
-			material = "concrete"
-		case "Fibre glass":
-			params.Coeff = constants.ThermalConductivity["fiberglass"]
-			material = "fiberglass"
-		case "PS foam":
-			params.Coeff = constants.ThermalConductivity["ps_foam"]
-			material = "ps_foam"
-		case "PE foam":
-			params.Coeff = constants.ThermalConductivity["pe_foam"]
-			material = "pe_foam"
-		case "Custom":
+		selectHandler(s)
+		if s == customOption {
 			v, err := strconv.ParseFloat(customMaterialInput.Text, 64)
 			if err != nil {
 				calculateButton.Disable()
@@ -141,6 +128,9 @@ func StartGUILoop() {
 				calculateButton.Enable()
 				params.Coeff = v
 			}
+		} else if m, err := constants.GetMaterial(materialKeys[s]); err == nil {
+			params.Coeff = m.Conductivity
+			material = m.Key
 		}
 
 		customMaterialInput.SetText(fmt.Sprintf("%.2f", params.Coeff))
@@ -163,9 +153,7 @@ func StartGUILoop() {
 		if err != nil {
 			calculateButton.Disable()
 		} else {
-			selected := strings.ToLower(materialTypeSelector.Selected)
-			selected = strings.ReplaceAll(selected, " ", "_")
-			newCoeff, err := calc.CalculateCoeffByThickness(selected, v)
+			newCoeff, err := calc.CalculateCoeffByThickness(material, v)
 			if err != nil {
 				calculateButton.Disable()
 			} else {
@@ -339,16 +327,27 @@ func StartGUILoop() {
 	// * -1 to make it positive
 	acPowerEntry.SetText(fmt.Sprintf("%.0f", -params.ACCoolingPower))
 
-	costLabel := widget.NewLabel(fmt.Sprintf("%.2f THB", totalCost))
+	costLabel := widget.NewLabel(formatCostRange(totalCost))
+	costLabel.Wrapping = fyne.TextWrapWord
 
-	montlyACCost := widget.NewLabel("0.00 THB")
+	montlyACCost := widget.NewLabel(formatCost(monthlyACCostTHB))
 
 	calculateButton = widget.NewButton("Calculate", func() {
-		mat, _ := constants.GetMaterialCost(material)
-		materialCost := calc.CalculateMaterialCost(params.W, params.H, params.L, thickness, mat)
-		costLabel.SetText(fmt.Sprintf("%.2f THB", materialCost))
+		billOfQuantities = calc.BillOfQuantities{}
+		totalCost = constants.PriceRange{}
+		if material != "" && thickness > 0 {
+			envelope := calc.RoomEnvelope(params.W, params.H, params.L, []calc.Layer{{Material: material, Thickness: thickness}}, true, false)
+			boq, err := calc.CalculateTakeoff(envelope, calc.DefaultTakeoffParams())
+			if err != nil {
+				fmt.Println(err)
+			} else {
+				billOfQuantities = boq
+				totalCost = boq.Total
+			}
+		}
+		costLabel.SetText(formatCostRange(totalCost))
 		if params.Location != currLocation {
-			temperature, err = weatherdata.GetCityTemperatureForecastNow(params.Location, freader.GetToken())
+			weather, err = weatherProvider.Forecast(params.Location)
 			if err != nil {
 				// create a pop up
 				errPop := a.NewWindow("Error")
@@ -356,6 +355,7 @@ func StartGUILoop() {
 				errPop.Show()
 				return
 			}
+			temperature = weather.Temp
 			currLocation = params.Location
 		}
 
@@ -371,30 +371,24 @@ func StartGUILoop() {
 			return
 		}
 
-		// ac params
-		var acParams *calc.ACParams
-		if params.ACEnabled {
-			acParams = &calc.ACParams{
-				Enabled:      true,
-				OnTime:       params.ACOnTime,
-				OffTime:      params.ACOffTime,
-				SetTemp:      params.ACSetTemp,
-				CoolingPower: params.ACCoolingPower,
-			}
-		}
-
 		// temp profile
-		_, inTemp, acProfile := calc.CalculateTemperatureProfile(params.W, params.H, params.L, *params.InsideTemp, [840]float64(temperature), params.Coeff, acParams)
-		resultsForDay.InTemp = [840]float64(inTemp)
+		res, err := scenario.RunWeather(currentScenario("Current"), weather)
+		if err != nil {
+			errPop := a.NewWindow("Error")
+			errPop.SetContent(widget.NewLabel("Error: " + err.Error()))
+			errPop.Show()
+			return
+		}
+		lastResult = res
+		lastResultStart = simulatedDay(weather)
+		resultsForDay.InTemp = [840]float64(res.InsideTemps)
 		resultsForDay.OutTemp = [840]float64(temperature)
 
 		// calculate cost
-		monthlyCost, _ := calc.CalculateACCostForSimulation(acParams, acProfile, 0)
-		montlyACCost.SetText(fmt.Sprintf("%.2f THB", monthlyCost))
+		monthlyACCostTHB = res.MonthlyACCost
+		montlyACCost.SetText(formatCost(monthlyACCostTHB))
 
-		newChart := calculateWithAC(params.W, params.H, params.L, params.InsideTemp, [840]float64(temperature), params.Coeff, acParams).Bytes()
-		imageElem.Resource = fyne.NewStaticResource("chart.png", newChart)
-		imageElem.Refresh()
+		chart.SetResult(res)
 	})
 	calculateButton.Disable()
 
@@ -406,8 +400,12 @@ func StartGUILoop() {
 		results.timeWidget.SetText(results.Time)
 		results.inTemp.SetText(fmt.Sprintf("%.1f °C", resultsForDay.InTemp[int(f)]))
 		results.outTemp.SetText(fmt.Sprintf("%.1f °C", resultsForDay.OutTemp[int(f)]))
+		chart.SetCursor(f)
 	}
 	timeSlider.Resize(fyne.Size{Width: 525, Height: 25})
+	chart.OnTapped = func(minute float64) {
+		timeSlider.SetValue(math.Round(minute))
+	}
 
 	// results
 	results.timeWidget = widget.NewLabel(results.Time)
@@ -473,18 +471,39 @@ func StartGUILoop() {
 
 		// graph
 		container.NewVBox(
-			widget.NewLabel("Graph"),
-			imageElem,
+			widget.NewLabel("Graph (drag to zoom, double click to reset)"),
+			chart,
 		),
 	))
 
+	w.SetMainMenu(fyne.NewMainMenu(
+		fileMenu(w),
+		fyne.NewMenu("Materials",
+			fyne.NewMenuItem("Add material...", func() {
+				showAddMaterialDialog(w, materialTypeSelector)
+			}),
+			fyne.NewMenuItem("Bill of quantities...", func() {
+				showBillOfQuantities(w, billOfQuantities)
+			}),
+		),
+		scenarioMenu(a, w, func() calc.Weather { return weather }),
+		weatherMenu(w, func() [840]float64 { return temperature }, func() {
+			// fetch again from the new provider on the next Calculate
+			currLocation = ""
+		}),
+		currencyMenu(func() {
+			costLabel.SetText(formatCostRange(totalCost))
+			montlyACCost.SetText(formatCost(monthlyACCostTHB))
+		}),
+	))
+
 	OnChangeHandler()
 	w.ShowAndRun()
 }
 
 func selectHandler(selected string) {
 	calculateButton.Enable()
-	if selected == "Custom" {
+	if selected == customOption {
 		customMaterialInput.Enable()
 	} else {
 		customMaterialInput.Disable()
@@ -505,40 +524,6 @@ func convertTime(step float64) string {
 	return fmt.Sprintf("%02d:%02d", hour+5, minute)
 }
 
-func calculate(w, h, d float64, insideTemp *float64, outsideTemps [840]float64, coeff float64) *bytes.Buffer {
-	return calculateWithAC(w, h, d, insideTemp, outsideTemps, coeff, nil)
-}
-
-func calculateWithAC(w, h, d float64, insideTemp *float64, outsideTemps [840]float64, coeff float64, acParams *calc.ACParams) *bytes.Buffer {
-	if insideTemp == nil {
-		insideTemp = &outsideTemps[0]
-	}
-
-	// get the inside temperature profile.
-	timeMinutes, insideProfile, acProfile := calc.CalculateTemperatureProfile(w, h, d, *insideTemp, outsideTemps, coeff, acParams)
-
-	ACprofile = acProfile
-
-	insidePts := make(plotter.XYs, len(timeMinutes))
-	outsidePts := make(plotter.XYs, len(timeMinutes))
-	for i, t := range timeMinutes {
-		insidePts[i].X = t
-		insidePts[i].Y = insideProfile[i]
-
-		outsidePts[i].X = t
-		outsidePts[i].Y = outsideTemps[i]
-
-		// doesnt work
-		// calculate the area under the curve for AC usage
-		// if acProfile != nil {
-		// }
-	}
-
-	// generate chart
-	imgData, _ := chartings.Plot(insidePts, outsidePts)
-	return imgData
-}
-
 // for testing
 func constant840() [840]float64 {
 	var arr [840]float64
@@ -0,0 +1,165 @@
+package gui
+
+import (
+	"errors"
+	"fmt"
+	"heat-transfer/calc"
+	"heat-transfer/constants"
+	"os"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// user material databases, loaded on top of the built-in defaults
+var materialFiles = []string{"./materials.json", "./materials.csv"}
+
+func loadMaterialFiles() {
+	for _, path := range materialFiles {
+		if err := constants.LoadMaterials(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			fmt.Println(err)
+		}
+	}
+}
+
+// customOption is the selector entry for a conductivity typed in by hand.
+const customOption = "Custom"
+
+// materialKeys maps the selector options to material keys.
+var materialKeys map[string]string
+
+// materialOptions lists the materials by name. Names shared by several
+// materials, or with the custom entry, carry the key as well.
+func materialOptions() []string {
+	list := constants.ListMaterials()
+	names := map[string]int{customOption: 1}
+	for _, m := range list {
+		names[m.Name]++
+	}
+
+	materialKeys = map[string]string{}
+	options := []string{}
+	for _, m := range list {
+		label := m.Name
+		if names[m.Name] > 1 {
+			label = fmt.Sprintf("%s (%s)", m.Name, m.Key)
+		}
+		materialKeys[label] = m.Key
+		options = append(options, label)
+	}
+	return append(options, customOption)
+}
+
+func showAddMaterialDialog(w fyne.Window, selector *widget.Select) {
+	key := widget.NewEntry()
+	name := widget.NewEntry()
+	conductivity := widget.NewEntry()
+	density := widget.NewEntry()
+	specificHeat := widget.NewEntry()
+	emissivity := widget.NewEntry()
+	emissivity.SetText("0.9")
+	costMin := widget.NewEntry()
+	costTypical := widget.NewEntry()
+	costMax := widget.NewEntry()
+	currency := widget.NewEntry()
+	currency.SetText(constants.BaseCurrency)
+	region := widget.NewEntry()
+	source := widget.NewEntry()
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Key", key),
+		widget.NewFormItem("Name", name),
+		widget.NewFormItem("Conductivity (W/m·K)", conductivity),
+		widget.NewFormItem("Density (kg/m³)", density),
+		widget.NewFormItem("Specific heat (J/kg·K)", specificHeat),
+		widget.NewFormItem("Emissivity", emissivity),
+		widget.NewFormItem("Min cost per m³", costMin),
+		widget.NewFormItem("Typical cost per m³", costTypical),
+		widget.NewFormItem("Max cost per m³", costMax),
+		widget.NewFormItem("Currency", currency),
+		widget.NewFormItem("Region", region),
+		widget.NewFormItem("Source", source),
+	}
+
+	dialog.ShowForm("Add material", "Add", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		m := constants.Material{
+			Key:    key.Text,
+			Name:   name.Text,
+			Source: source.Text,
+			Cost: constants.PriceRange{
+				Currency: currency.Text,
+				Region:   region.Text,
+			},
+		}
+		for _, f := range []struct {
+			label string
+			entry *widget.Entry
+			dst   *float64
+		}{
+			{"conductivity", conductivity, &m.Conductivity},
+			{"density", density, &m.Density},
+			{"specific heat", specificHeat, &m.SpecificHeat},
+			{"emissivity", emissivity, &m.Emissivity},
+			{"min cost", costMin, &m.Cost.Min},
+			{"typical cost", costTypical, &m.Cost.Typical},
+			{"max cost", costMax, &m.Cost.Max},
+		} {
+			if f.entry.Text == "" {
+				continue
+			}
+			v, err := strconv.ParseFloat(f.entry.Text, 64)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("invalid %s: %s", f.label, f.entry.Text), w)
+				return
+			}
+			*f.dst = v
+		}
+
+		if err := constants.AddMaterial(m); err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+
+		selector.Options = materialOptions()
+		selector.Refresh()
+	}, w)
+}
+
+func showBillOfQuantities(w fyne.Window, boq calc.BillOfQuantities) {
+	if len(boq.Items) == 0 {
+		dialog.ShowInformation("Bill of quantities", "Select a material and thickness, then press Calculate.", w)
+		return
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%-8s %-12s %8s %8s %8s %s\n", "Element", "Material", "Net m²", "m³", "Order m³", "Total")
+	for _, it := range boq.Items {
+		fmt.Fprintf(&sb, "%-8s %-12s %8.2f %8.3f %8.3f %s\n", it.Element, it.Material, it.NetArea, it.NetVolume, it.OrderVolume, formatCostRange(it.Total))
+	}
+	fmt.Fprintf(&sb, "\nMaterial: %s\nLabour:   %s\nTotal:    %s\n", formatCostRange(boq.MaterialTotal), formatCostRange(boq.LabourTotal), formatCostRange(boq.Total))
+
+	grid := widget.NewTextGridFromString(sb.String())
+	save := widget.NewButton("Save CSV...", func() {
+		dialog.ShowFileSave(func(wc fyne.URIWriteCloser, err error) {
+			if err != nil || wc == nil {
+				return
+			}
+			defer wc.Close()
+			if err := boq.WriteCSV(wc); err != nil {
+				dialog.ShowError(err, w)
+			}
+		}, w)
+	})
+
+	d := dialog.NewCustom("Bill of quantities", "Close", container.NewVBox(container.NewHScroll(grid), save), w)
+	d.Resize(fyne.NewSize(620, 400))
+	d.Show()
+}
@@ -0,0 +1,131 @@
+package gui
+
+import (
+	"bytes"
+	"fmt"
+	"heat-transfer/calc"
+	"heat-transfer/chartings"
+	"heat-transfer/export"
+	"heat-transfer/finance"
+	"heat-transfer/scenario"
+	"io"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+var savedScenarios []scenario.Scenario
+
+// currentScenario captures the inputs currently entered in the window.
+func currentScenario(name string) scenario.Scenario {
+	s := scenario.Scenario{
+		Name:   name,
+		Width:  params.W,
+		Height: params.H,
+		Depth:  params.L,
+		Roof:   true,
+		// the coefficient shown in the window is what gets simulated
+		Coeff: params.Coeff,
+	}
+
+	if material != "" && thickness > 0 {
+		s.Layers = []calc.Layer{{Material: material, Thickness: thickness}}
+	}
+
+	if params.InsideTemp != nil {
+		v := *params.InsideTemp
+		s.InsideTemp = &v
+	}
+
+	if params.ACEnabled {
+		s.AC = &calc.ACParams{
+			Enabled:      true,
+			OnTime:       params.ACOnTime,
+			OffTime:      params.ACOffTime,
+			SetTemp:      params.ACSetTemp,
+			CoolingPower: params.ACCoolingPower,
+		}
+	}
+
+	return s
+}
+
+func scenarioMenu(a fyne.App, w fyne.Window, weather func() calc.Weather) *fyne.Menu {
+	return fyne.NewMenu("Scenarios",
+		fyne.NewMenuItem("Add current scenario...", func() {
+			name := widget.NewEntry()
+			name.SetText(fmt.Sprintf("Scenario %d", len(savedScenarios)+1))
+			dialog.ShowForm("Add scenario", "Add", "Cancel", []*widget.FormItem{widget.NewFormItem("Name", name)}, func(ok bool) {
+				if !ok {
+					return
+				}
+				s := currentScenario(name.Text)
+				if err := s.Validate(); err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+				savedScenarios = append(savedScenarios, s)
+			}, w)
+		}),
+		fyne.NewMenuItem("Compare...", func() {
+			showComparison(a, w, weather())
+		}),
+		fyne.NewMenuItem("Clear scenarios", func() {
+			savedScenarios = nil
+		}),
+	)
+}
+
+func showComparison(a fyne.App, parent fyne.Window, weather calc.Weather) {
+	if len(savedScenarios) < 2 {
+		dialog.ShowInformation("Compare scenarios", "Add at least two scenarios. The first one is the baseline.", parent)
+		return
+	}
+
+	comparison, err := scenario.Compare(savedScenarios, weather.Temp, finance.DefaultAssumptions())
+	if err != nil {
+		dialog.ShowError(err, parent)
+		return
+	}
+
+	var table bytes.Buffer
+	if err := comparison.WriteTable(&table); err != nil {
+		dialog.ShowError(err, parent)
+		return
+	}
+
+	base := comparison.Results[0]
+	series := make([]chartings.Series, 0, len(comparison.Results))
+	for _, r := range comparison.Results {
+		series = append(series, chartings.Series{Name: r.Scenario.Name, Points: chartings.XYs(r.TimeMinutes, r.InsideTemps)})
+	}
+	chart, err := chartings.PlotComparison(chartings.XYs(base.TimeMinutes, base.OutsideTemps), series)
+	if err != nil {
+		dialog.ShowError(err, parent)
+		return
+	}
+
+	image := canvas.NewImageFromReader(chart, "comparison.png")
+	image.FillMode = canvas.ImageFillOriginal
+
+	w := a.NewWindow("Scenario comparison")
+	exportButton := widget.NewButton("Export Excel...", func() {
+		saveFile(w, "comparison.xlsx", func(wr io.Writer) error {
+			wb, err := export.ComparisonWorkbook(comparison, simulatedDay(weather))
+			if err != nil {
+				return err
+			}
+			return wb.Write(wr)
+		})
+	})
+	w.SetContent(container.NewVBox(
+		container.NewHScroll(widget.NewTextGridFromString(table.String())),
+		image,
+		exportButton,
+	))
+	w.Resize(fyne.NewSize(900, 600))
+	w.Show()
+}
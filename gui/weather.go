@@ -0,0 +1,162 @@
+package gui
+
+import (
+	"bytes"
+	"heat-transfer/climate"
+	freader "heat-transfer/fReader"
+	"heat-transfer/interop"
+	"heat-transfer/quality"
+	weatherdata "heat-transfer/weatherData"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// base temperatures of the degree-days in the climate summary, °C
+var climateBases = []float64{18, 24}
+
+// source of the forecast fetched on Calculate
+var weatherProvider weatherdata.Provider
+
+var providerLabels = []struct{ name, label string }{
+	{weatherdata.ProviderOpenWeatherMap, "OpenWeatherMap"},
+	{weatherdata.ProviderOpenMeteo, "Open-Meteo (no API key)"},
+}
+
+// defaultProvider keeps OpenWeatherMap when ./token exists and falls back to
+// Open-Meteo, which needs no key.
+func defaultProvider() weatherdata.Provider {
+	if token, err := freader.ReadToken("./token"); err == nil && token != "" {
+		return weatherdata.OpenWeatherMap{APIKey: token}
+	}
+	return weatherdata.NewOpenMeteo()
+}
+
+func weatherMenu(w fyne.Window, outsideTemps func() [840]float64, onChanged func()) *fyne.Menu {
+	menu := fyne.NewMenu("Weather")
+	for _, p := range providerLabels {
+		item := fyne.NewMenuItem(p.label, nil)
+		item.Checked = weatherProvider.Name() == p.name
+		item.Action = func() {
+			token, _ := freader.ReadToken("./token")
+			provider, err := weatherdata.NewProvider(p.name, token)
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			weatherProvider = provider
+			for i, other := range menu.Items {
+				other.Checked = providerLabels[i].name == p.name
+			}
+			menu.Refresh()
+			onChanged()
+		}
+		menu.Items = append(menu.Items, item)
+	}
+
+	menu.Items = append(menu.Items,
+		fyne.NewMenuItemSeparator(),
+		interpolationMenuItem(onChanged),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Climate summary of the current day...", func() {
+			showClimateSummary(w, "Current day", climate.FromDay(time.Now(), outsideTemps()))
+		}),
+		fyne.NewMenuItem("Climate summary of an EPW file...", func() {
+			dialog.ShowFileOpen(func(rc fyne.URIReadCloser, err error) {
+				if err != nil || rc == nil {
+					return
+				}
+				defer rc.Close()
+				rec, err := climate.ReadEPW(rc)
+				if err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+				title := rc.URI().Name()
+				if loc := rec.Location; loc.City != "" {
+					title = loc.City + ", " + loc.Country
+				}
+				showClimateSummary(w, title, rec)
+			}, w)
+		}),
+	)
+	return menu
+}
+
+var interpolationLabels = []struct {
+	method interop.Method
+	label  string
+}{
+	{interop.CatmullRom, "Catmull-Rom (original)"},
+	{interop.Monotone, "Monotone cubic (no overshoot)"},
+	{interop.Spline, "Natural cubic spline"},
+	{interop.Linear, "Linear"},
+}
+
+// interpolationMenuItem sets interop.Default, which the providers use to
+// resample the hourly forecast.
+func interpolationMenuItem(onChanged func()) *fyne.MenuItem {
+	sub := fyne.NewMenu("")
+	refresh := func() {
+		for i, l := range interpolationLabels {
+			sub.Items[i].Checked = interop.Default.Method == l.method
+		}
+		sub.Items[len(sub.Items)-1].Checked = interop.Default.Smoothing > 0
+		sub.Refresh()
+	}
+
+	for _, l := range interpolationLabels {
+		sub.Items = append(sub.Items, fyne.NewMenuItem(l.label, func() {
+			interop.Default.Method = l.method
+			refresh()
+			onChanged()
+		}))
+	}
+	sub.Items = append(sub.Items, fyne.NewMenuItemSeparator(), fyne.NewMenuItem("Smooth 30 minutes (lowers peaks)", func() {
+		if interop.Default.Smoothing > 0 {
+			interop.Default.Smoothing = 0
+		} else {
+			interop.Default.Smoothing = interop.LegacyOptions.Smoothing
+		}
+		refresh()
+		onChanged()
+	}))
+	refresh()
+
+	item := fyne.NewMenuItem("Interpolation", nil)
+	item.ChildMenu = sub
+	return item
+}
+
+// showQualityReports shows what the quality checks changed in a forecast
+// before it is simulated.
+func showQualityReports(w fyne.Window) {
+	weatherdata.ReportQuality = func(source string, r quality.Report) {
+		var text bytes.Buffer
+		r.WriteText(&text)
+		dialog.ShowInformation("Weather data for "+source, text.String(), w)
+	}
+}
+
+// showCacheReports tells when an offline forecast comes from the cache.
+func showCacheReports(w fyne.Window) {
+	weatherdata.ReportCache = func(err error) {
+		dialog.ShowInformation("Weather cache", err.Error(), w)
+	}
+}
+
+func showClimateSummary(w fyne.Window, title string, rec climate.Record) {
+	var table bytes.Buffer
+	if err := climate.Summarize(rec, climateBases).WriteText(&table); err != nil {
+		dialog.ShowError(err, w)
+		return
+	}
+
+	grid := widget.NewTextGridFromString(table.String())
+	d := dialog.NewCustom("Climate summary: "+title, "Close", container.NewScroll(grid), w)
+	d.Resize(fyne.NewSize(720, 420))
+	d.Show()
+}
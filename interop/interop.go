@@ -1,109 +1,73 @@
 package interop
 
-import "math"
+import "fmt"
 
-func MovingWindowInterpolateTemperature(hourlyTemps []float64) [840]float64 {
-	minutelyTemps := [840]float64{}
+// minutes from 05:00 to 19:00, the simulated day
+const dayMinutes = 840
 
-	expandedTemps := make([]float64, len(hourlyTemps)+4)
-
-	if len(hourlyTemps) >= 2 {
-		slope := hourlyTemps[1] - hourlyTemps[0]
-		expandedTemps[0] = hourlyTemps[0] - 2*slope
-		expandedTemps[1] = hourlyTemps[0] - slope
-	} else {
-		expandedTemps[0] = hourlyTemps[0]
-		expandedTemps[1] = hourlyTemps[0]
-	}
-
-	copy(expandedTemps[2:2+len(hourlyTemps)], hourlyTemps)
-
-	if len(hourlyTemps) >= 2 {
-		slope := hourlyTemps[len(hourlyTemps)-1] - hourlyTemps[len(hourlyTemps)-2]
-		expandedTemps[len(hourlyTemps)+2] = hourlyTemps[len(hourlyTemps)-1] + slope
-		expandedTemps[len(hourlyTemps)+3] = hourlyTemps[len(hourlyTemps)-1] + 2*slope
-	} else {
-		expandedTemps[len(hourlyTemps)+2] = hourlyTemps[len(hourlyTemps)-1]
-		expandedTemps[len(hourlyTemps)+3] = hourlyTemps[len(hourlyTemps)-1]
-	}
-
-	// cubic spline
-	for minute := 0; minute < 840; minute++ {
-		hourIndex := minute / 60
-		minuteInHour := minute % 60
-
-		// first and last hr
-		if hourIndex >= len(hourlyTemps)-1 {
-			minutelyTemps[minute] = hourlyTemps[len(hourlyTemps)-1]
-			continue
-		}
-
-		// cubic interop
-		t := float64(minuteInHour) / 60.0
-
-		// control points
-		idx := hourIndex + 2
-		p0 := expandedTemps[idx-1]
-		p1 := expandedTemps[idx]
-		p2 := expandedTemps[idx+1]
-		p3 := expandedTemps[idx+2]
-
-		// apply
-		t2 := t * t
-		t3 := t2 * t
-
-		// hermite basis fn
-		h00 := 2*t3 - 3*t2 + 1
-		h10 := t3 - 2*t2 + t
-		h01 := -2*t3 + 3*t2
-		h11 := t3 - t2
-
-		// tangent, catmull rom
-		m0 := 0.5 * (p2 - p0)
-		m1 := 0.5 * (p3 - p1)
+// Options choose how hourly samples become the per-minute simulation input.
+type Options struct {
+	Method Method `json:"method"` // CatmullRom when empty
+	// Gaussian smoothing window either side of each minute, 0 disables it.
+	// Smoothing lowers the peaks that size the AC.
+	Smoothing int `json:"smoothing,omitempty"`
+}
 
-		// interpolate
-		interpolatedTemp := h00*p1 + h10*m0 + h01*p2 + h11*m1
+// DefaultOptions keep the hourly peaks: Catmull-Rom without smoothing.
+var DefaultOptions = Options{Method: CatmullRom}
 
-		// more smooth
-		if minuteInHour < 5 || minuteInHour > 55 {
-			if hourIndex > 0 && hourIndex < len(hourlyTemps)-2 {
-				windowAvg := (expandedTemps[idx-1] + expandedTemps[idx] +
-					expandedTemps[idx+1] + expandedTemps[idx+2]) / 4.0
+// LegacyOptions reproduce the original resampling, Catmull-Rom with a
+// 30-minute smoothing pass, for comparing with earlier results.
+var LegacyOptions = Options{Method: CatmullRom, Smoothing: 30}
 
-				// blend weight
-				blendFactor := 0.0
-				if minuteInHour < 5 {
-					blendFactor = 1.0 - float64(minuteInHour)/5.0
-				} else {
-					blendFactor = (float64(minuteInHour) - 55.0) / 5.0
-				}
+// Default is used wherever the caller does not pass options, such as weather
+// providers and files.
+var Default = DefaultOptions
 
-				// blend with window avg
-				interpolatedTemp = interpolatedTemp*(1-blendFactor*0.3) + windowAvg*(blendFactor*0.3)
-			}
+func (o Options) Validate() error {
+	if o.Method != "" {
+		if _, err := ParseMethod(string(o.Method)); err != nil {
+			return err
 		}
-
-		minutelyTemps[minute] = interpolatedTemp
 	}
+	if o.Smoothing < 0 || o.Smoothing > dayMinutes/2 {
+		return fmt.Errorf("smoothing must be between 0 and %d minutes, got %d", dayMinutes/2, o.Smoothing)
+	}
+	return nil
+}
 
-	// last pass
-	smoothedTemps := [840]float64{}
-	copy(smoothedTemps[:], minutelyTemps[:])
-
-	windowSize := 30
-	for i := windowSize; i < 840-windowSize; i++ {
-		sum := 0.0
-		weightSum := 0.0
+// Day interpolates samples taken minutes[i] after 05:00 to each minute of the
+// simulated day, then smooths the result.
+func (o Options) Day(minutes, values []float64) ([840]float64, error) {
+	var day [840]float64
+	if err := o.Validate(); err != nil {
+		return day, err
+	}
+	out, err := Resample(o.Method, minutes, values, 0, 1, dayMinutes)
+	if err != nil {
+		return day, err
+	}
+	copy(day[:], Smooth(out, o.Smoothing))
+	return day, nil
+}
 
-		for j := -windowSize; j <= windowSize; j++ {
-			weight := math.Exp(-float64(j*j) / (2.0 * float64(windowSize/3) * float64(windowSize/3)))
-			sum += minutelyTemps[i+j] * weight
-			weightSum += weight
-		}
+// DayHourly is Day for consecutive hours from 05:00.
+func (o Options) DayHourly(hourly []float64) ([840]float64, error) {
+	return o.Day(HourlyMinutes(len(hourly)), hourly)
+}
 
-		smoothedTemps[i] = sum / weightSum
+// HourlyMinutes are the timestamps of n consecutive hourly samples from 05:00.
+func HourlyMinutes(n int) []float64 {
+	minutes := make([]float64, n)
+	for i := range minutes {
+		minutes[i] = float64(i * 60)
 	}
+	return minutes
+}
 
-	return smoothedTemps
+// MovingWindowInterpolateTemperature resamples consecutive hourly values from
+// 05:00 with the Default options. It fails without values; check untrusted
+// data with the quality package first.
+func MovingWindowInterpolateTemperature(hourlyTemps []float64) ([840]float64, error) {
+	return Default.DayHourly(hourlyTemps)
 }
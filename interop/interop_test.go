@@ -0,0 +1,196 @@
+package interop
+
+import (
+	"math"
+	"testing"
+)
+
+// a hot afternoon with a sharp peak, hourly from 05:00
+var peakDay = []float64{25, 25.5, 27, 29, 31, 33, 35, 38, 38.2, 36, 33, 31, 30, 29, 28}
+
+func minutes(n int) []float64 {
+	at := make([]float64, n)
+	for i := range at {
+		at[i] = float64(i)
+	}
+	return at
+}
+
+func TestInterpolateThroughSamples(t *testing.T) {
+	x := HourlyMinutes(len(peakDay))
+	// Catmull-Rom is left out, its blend pulls interior samples towards the
+	// window average
+	for _, m := range []Method{Linear, Monotone, Spline} {
+		t.Run(string(m), func(t *testing.T) {
+			out, err := Interpolate(m, x, peakDay, x)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, v := range out {
+				if math.Abs(v-peakDay[i]) > 1e-9 {
+					t.Errorf("%g at sample %d, want %g", v, i, peakDay[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMonotoneNoOvershoot(t *testing.T) {
+	tests := []struct {
+		name string
+		x, y []float64
+	}{
+		{"peak", HourlyMinutes(len(peakDay)), peakDay},
+		{"step", HourlyMinutes(6), []float64{20, 20, 20, 30, 30, 30}},
+		{"uneven spacing", []float64{0, 10, 200, 230, 600}, []float64{25, 26, 35, 35.5, 27}},
+		{"plateau", HourlyMinutes(5), []float64{30, 32, 32, 32, 28}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at := minutes(int(tt.x[len(tt.x)-1]) + 1)
+			out, err := Interpolate(Monotone, tt.x, tt.y, at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for k, a := range at {
+				// the interval holding a
+				i := 0
+				for i < len(tt.x)-2 && tt.x[i+1] <= a {
+					i++
+				}
+				lo, hi := math.Min(tt.y[i], tt.y[i+1]), math.Max(tt.y[i], tt.y[i+1])
+				if out[k] < lo-1e-9 || out[k] > hi+1e-9 {
+					t.Fatalf("minute %g: %g outside the samples %g to %g", a, out[k], lo, hi)
+				}
+			}
+		})
+	}
+
+	// the spline does overshoot the peak, which is why monotone exists
+	spline, err := Interpolate(Spline, HourlyMinutes(len(peakDay)), peakDay, minutes(841))
+	if err != nil {
+		t.Fatal(err)
+	}
+	peak := 0.0
+	for _, v := range spline {
+		peak = math.Max(peak, v)
+	}
+	if peak <= 38.2 {
+		t.Errorf("spline peak %g, expected above the 38.2 sample", peak)
+	}
+}
+
+func TestInterpolateExact(t *testing.T) {
+	x := []float64{0, 60, 180, 240}
+	tests := []struct {
+		method Method
+		y      []float64
+	}{
+		// linear data stays linear with every method but Catmull-Rom, whose
+		// blend pulls towards the window average
+		{Linear, []float64{10, 12, 16, 18}},
+		{Monotone, []float64{10, 12, 16, 18}},
+		{Spline, []float64{10, 12, 16, 18}},
+		// constant data is constant with every method
+		{CatmullRom, []float64{20, 20, 20, 20}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			out, err := Interpolate(tt.method, x, tt.y, minutes(241))
+			if err != nil {
+				t.Fatal(err)
+			}
+			slope := (tt.y[3] - tt.y[0]) / 240
+			for m, v := range out {
+				if want := tt.y[0] + slope*float64(m); math.Abs(v-want) > 1e-9 {
+					t.Fatalf("minute %d: %g, want %g", m, v, want)
+				}
+			}
+		})
+	}
+}
+
+func TestInterpolateHoldsEnds(t *testing.T) {
+	out, err := Interpolate(Spline, []float64{60, 120}, []float64{20, 30}, []float64{0, 60, 120, 500})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out[0] != 20 || out[1] != 20 || out[2] != 30 || out[3] != 30 {
+		t.Errorf("%v, want the first and last values held outside the samples", out)
+	}
+}
+
+func TestInterpolateErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		method Method
+		x, y   []float64
+	}{
+		{"no values", Linear, nil, nil},
+		{"lengths differ", Linear, []float64{0, 60}, []float64{20}},
+		{"not increasing", Linear, []float64{0, 60, 60}, []float64{20, 21, 22}},
+		{"unknown method", "cubic", []float64{0, 60}, []float64{20, 21}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Interpolate(tt.method, tt.x, tt.y, []float64{30}); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	if _, err := MovingWindowInterpolateTemperature(nil); err == nil {
+		t.Error("an empty day should fail, not come back as zeros")
+	}
+	if _, err := (Options{Smoothing: -1}).Day([]float64{0}, []float64{20}); err == nil {
+		t.Error("negative smoothing should fail")
+	}
+}
+
+func TestSmooth(t *testing.T) {
+	values := make([]float64, 100)
+	values[50] = 10
+
+	out := Smooth(values, 5)
+	sum := 0.0
+	for _, v := range out {
+		sum += v
+	}
+	if math.Abs(sum-10) > 1e-9 {
+		t.Errorf("smoothing changed the total from 10 to %g", sum)
+	}
+	if out[50] >= 10 || out[50] <= out[49] || out[49] != out[51] {
+		t.Errorf("peak %g with neighbours %g and %g, want a lower symmetric peak", out[50], out[49], out[51])
+	}
+
+	// the edges lack a full window and are kept
+	values[2], values[97] = 5, 5
+	out = Smooth(values, 5)
+	if out[2] != 5 || out[97] != 5 {
+		t.Errorf("edges %g and %g, want them unchanged", out[2], out[97])
+	}
+
+	if out := Smooth(values, 0); &out[0] == &values[0] || out[50] != 10 {
+		t.Error("window 0 should return an unchanged copy")
+	}
+}
+
+func TestDefaultKeepsPeaks(t *testing.T) {
+	peak := func(o Options) float64 {
+		day, err := o.DayHourly(peakDay)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := 0.0
+		for _, v := range day {
+			p = math.Max(p, v)
+		}
+		return p
+	}
+	if p := peak(DefaultOptions); p < 38.2 {
+		t.Errorf("default peak %g, want at least the 38.2 sample", p)
+	}
+	if p := peak(LegacyOptions); p >= peak(DefaultOptions) {
+		t.Errorf("legacy smoothing peak %g should be below the default", p)
+	}
+}
@@ -0,0 +1,220 @@
+package interop
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Method selects how samples are interpolated between their timestamps.
+type Method string
+
+const (
+	// Linear joins the samples with straight lines.
+	Linear Method = "linear"
+	// Monotone is a Fritsch-Carlson cubic, it never overshoots the samples
+	// so peaks stay at the observed maximum.
+	Monotone Method = "monotone"
+	// Spline is a natural cubic spline, smooth in the second derivative.
+	Spline Method = "spline"
+	// CatmullRom is the original method: a Catmull-Rom spline blended with
+	// the four surrounding samples near each of them.
+	CatmullRom Method = "catmull-rom"
+)
+
+var Methods = []Method{Linear, Monotone, Spline, CatmullRom}
+
+func ParseMethod(s string) (Method, error) {
+	for _, m := range Methods {
+		if strings.EqualFold(s, string(m)) {
+			return m, nil
+		}
+	}
+	names := make([]string, len(Methods))
+	for i, m := range Methods {
+		names[i] = string(m)
+	}
+	return "", fmt.Errorf("unknown interpolation method %q, use one of %s", s, strings.Join(names, ", "))
+}
+
+// Interpolate evaluates the curve through the samples (x[i], y[i]) at each of
+// at. x must be strictly increasing, in any unit. Outside the samples the
+// first and last values are held.
+func Interpolate(method Method, x, y, at []float64) ([]float64, error) {
+	if len(x) != len(y) {
+		return nil, fmt.Errorf("%d timestamps for %d values", len(x), len(y))
+	}
+	if len(x) == 0 {
+		return nil, errors.New("no values to interpolate")
+	}
+	for i := 1; i < len(x); i++ {
+		if !(x[i] > x[i-1]) {
+			return nil, fmt.Errorf("timestamps must increase, %g follows %g", x[i], x[i-1])
+		}
+	}
+
+	var curve func(i int, t float64) float64
+	switch method {
+	case Linear:
+		curve = func(i int, t float64) float64 { return y[i] + t*(y[i+1]-y[i]) }
+	case Monotone:
+		curve = hermite(x, y, monotoneTangents(x, y))
+	case Spline:
+		curve = naturalSpline(x, y)
+	case CatmullRom, "":
+		curve = catmullRom(x, y)
+	default:
+		return nil, fmt.Errorf("unknown interpolation method %q", method)
+	}
+
+	out := make([]float64, len(at))
+	n := len(x)
+	for k, a := range at {
+		switch {
+		case a <= x[0]:
+			out[k] = y[0]
+		case a >= x[n-1]:
+			out[k] = y[n-1]
+		default:
+			// last sample at or before a
+			lo, hi := 0, n-1
+			for hi-lo > 1 {
+				mid := (lo + hi) / 2
+				if x[mid] <= a {
+					lo = mid
+				} else {
+					hi = mid
+				}
+			}
+			out[k] = curve(lo, (a-x[lo])/(x[lo+1]-x[lo]))
+		}
+	}
+	return out, nil
+}
+
+// Resample interpolates to n points step apart from start, in the unit of x.
+func Resample(method Method, x, y []float64, start, step float64, n int) ([]float64, error) {
+	if step <= 0 {
+		return nil, fmt.Errorf("step must be positive, got %g", step)
+	}
+	at := make([]float64, n)
+	for i := range at {
+		at[i] = start + float64(i)*step
+	}
+	return Interpolate(method, x, y, at)
+}
+
+// hermite returns the cubic Hermite curve with tangents m, in units of y per
+// unit of x, on interval i at fraction t.
+func hermite(x, y, m []float64) func(i int, t float64) float64 {
+	return func(i int, t float64) float64 {
+		h := x[i+1] - x[i]
+		t2 := t * t
+		t3 := t2 * t
+		h00 := 2*t3 - 3*t2 + 1
+		h10 := t3 - 2*t2 + t
+		h01 := -2*t3 + 3*t2
+		h11 := t3 - t2
+		return h00*y[i] + h10*h*m[i] + h01*y[i+1] + h11*h*m[i+1]
+	}
+}
+
+// monotoneTangents follows Fritsch and Carlson: tangents are zero at local
+// extrema and limited so no interval overshoots.
+func monotoneTangents(x, y []float64) []float64 {
+	n := len(x)
+	m := make([]float64, n)
+	if n < 2 {
+		return m
+	}
+
+	delta := make([]float64, n-1)
+	for i := range delta {
+		delta[i] = (y[i+1] - y[i]) / (x[i+1] - x[i])
+	}
+	m[0], m[n-1] = delta[0], delta[n-2]
+	for i := 1; i < n-1; i++ {
+		if delta[i-1]*delta[i] > 0 {
+			// weighted harmonic mean, for uneven spacing
+			h0, h1 := x[i]-x[i-1], x[i+1]-x[i]
+			w0, w1 := 2*h1+h0, h1+2*h0
+			m[i] = (w0 + w1) / (w0/delta[i-1] + w1/delta[i])
+		}
+	}
+
+	for i, d := range delta {
+		if d == 0 {
+			m[i], m[i+1] = 0, 0
+			continue
+		}
+		a, b := m[i]/d, m[i+1]/d
+		if s := a*a + b*b; s > 9 {
+			tau := 3 / math.Sqrt(s)
+			m[i], m[i+1] = tau*a*d, tau*b*d
+		}
+	}
+	return m
+}
+
+// naturalSpline solves for the second derivatives with zero curvature at both
+// ends.
+func naturalSpline(x, y []float64) func(i int, t float64) float64 {
+	n := len(x)
+	m := make([]float64, n) // second derivatives
+	if n > 2 {
+		// tridiagonal system for m[1..n-2], Thomas algorithm
+		c := make([]float64, n)
+		d := make([]float64, n)
+		for i := 1; i < n-1; i++ {
+			h0, h1 := x[i]-x[i-1], x[i+1]-x[i]
+			a, b := h0, 2*(h0+h1)
+			r := 6 * ((y[i+1]-y[i])/h1 - (y[i]-y[i-1])/h0)
+			denom := b - a*c[i-1]
+			c[i] = h1 / denom
+			d[i] = (r - a*d[i-1]) / denom
+		}
+		for i := n - 2; i >= 1; i-- {
+			m[i] = d[i] - c[i]*m[i+1]
+		}
+	}
+
+	return func(i int, t float64) float64 {
+		h := x[i+1] - x[i]
+		u := 1 - t
+		return u*y[i] + t*y[i+1] + h*h/6*((u*u*u-u)*m[i]+(t*t*t-t)*m[i+1])
+	}
+}
+
+// catmullRom extends the samples by their end slopes and uses central
+// differences as tangents. Within a twelfth of an interval of an interior
+// sample the curve is pulled towards the mean of the four surrounding
+// samples, as the original hourly resampling did.
+func catmullRom(x, y []float64) func(i int, t float64) float64 {
+	n := len(x)
+	m := make([]float64, n)
+	if n >= 2 {
+		m[0] = (y[1] - y[0]) / (x[1] - x[0])
+		m[n-1] = (y[n-1] - y[n-2]) / (x[n-1] - x[n-2])
+		for i := 1; i < n-1; i++ {
+			m[i] = (y[i+1] - y[i-1]) / (x[i+1] - x[i-1])
+		}
+	}
+	curve := hermite(x, y, m)
+
+	const edge = 5.0 / 60
+	return func(i int, t float64) float64 {
+		v := curve(i, t)
+		if i == 0 || i >= n-2 || (t >= edge && t <= 1-edge) {
+			return v
+		}
+		windowAvg := (y[i-1] + y[i] + y[i+1] + y[i+2]) / 4
+		blend := 0.0
+		if t < edge {
+			blend = 1 - t/edge
+		} else {
+			blend = (t - (1 - edge)) / edge
+		}
+		return v*(1-blend*0.3) + windowAvg*(blend*0.3)
+	}
+}
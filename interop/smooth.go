@@ -0,0 +1,30 @@
+package interop
+
+import "math"
+
+// Smooth applies a Gaussian filter of sigma window/3 over window samples
+// either side. The first and last window samples, which lack a full window,
+// are kept as they are.
+func Smooth(values []float64, window int) []float64 {
+	smoothed := append([]float64(nil), values...)
+	if window <= 0 {
+		return smoothed
+	}
+
+	sigma := float64(window) / 3
+	weights := make([]float64, 2*window+1)
+	weightSum := 0.0
+	for j := -window; j <= window; j++ {
+		weights[j+window] = math.Exp(-float64(j*j) / (2 * sigma * sigma))
+		weightSum += weights[j+window]
+	}
+
+	for i := window; i < len(values)-window; i++ {
+		sum := 0.0
+		for j := -window; j <= window; j++ {
+			sum += values[i+j] * weights[j+window]
+		}
+		smoothed[i] = sum / weightSum
+	}
+	return smoothed
+}
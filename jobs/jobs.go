@@ -0,0 +1,260 @@
+// Package jobs runs long simulations in the background with bounded
+// concurrency, progress reporting and cancellation. Finished jobs keep their
+// result until they expire.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"runtime"
+	"sync"
+	"time"
+)
+
+type State string
+
+const (
+	Queued    State = "queued"
+	Running   State = "running"
+	Succeeded State = "succeeded"
+	Failed    State = "failed"
+	Canceled  State = "canceled"
+)
+
+// Finished reports whether the job will not change state any more.
+func (s State) Finished() bool {
+	return s == Succeeded || s == Failed || s == Canceled
+}
+
+var (
+	ErrNotFound  = errors.New("job not found")
+	ErrQueueFull = errors.New("too many queued jobs")
+)
+
+// Func is the work of a job. It must return once ctx is cancelled and may
+// call progress as often as it likes.
+type Func func(ctx context.Context, progress func(done, total int)) (any, error)
+
+type Config struct {
+	Workers   int           // jobs running at once, 0 uses one per CPU
+	MaxQueued int           // jobs waiting for a worker, 0 means 100
+	TTL       time.Duration // how long a finished job is kept, 0 means one hour
+}
+
+// Status is a snapshot of a job.
+type Status struct {
+	ID       string     `json:"id"`
+	Kind     string     `json:"kind"`
+	State    State      `json:"state"`
+	Done     int        `json:"done"`
+	Total    int        `json:"total"`
+	Progress float64    `json:"progress"` // 0 to 1
+	Error    string     `json:"error,omitempty"`
+	Created  time.Time  `json:"created"`
+	Started  *time.Time `json:"started,omitempty"`
+	Finished *time.Time `json:"finished,omitempty"`
+	Expires  *time.Time `json:"expires,omitempty"`
+}
+
+type job struct {
+	status Status
+	result any
+	cancel context.CancelFunc
+}
+
+type Queue struct {
+	cfg   Config
+	slots chan struct{}
+	ctx   context.Context
+	stop  context.CancelFunc
+	wg    sync.WaitGroup
+
+	mu     sync.Mutex
+	jobs   map[string]*job
+	queued int
+}
+
+func NewQueue(cfg Config) *Queue {
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.NumCPU()
+	}
+	if cfg.MaxQueued <= 0 {
+		cfg.MaxQueued = 100
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = time.Hour
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	return &Queue{
+		cfg:   cfg,
+		slots: make(chan struct{}, cfg.Workers),
+		ctx:   ctx,
+		stop:  stop,
+		jobs:  map[string]*job{},
+	}
+}
+
+// Submit queues fn and returns at once.
+func (q *Queue) Submit(kind string, fn Func) (Status, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.expire(time.Now())
+	if q.ctx.Err() != nil {
+		return Status{}, errors.New("job queue is closed")
+	}
+	if q.queued >= q.cfg.MaxQueued {
+		return Status{}, ErrQueueFull
+	}
+
+	id, err := newID()
+	if err != nil {
+		return Status{}, err
+	}
+	ctx, cancel := context.WithCancel(q.ctx)
+	j := &job{
+		status: Status{ID: id, Kind: kind, State: Queued, Created: time.Now()},
+		cancel: cancel,
+	}
+	q.jobs[id] = j
+	q.queued++
+
+	q.wg.Add(1)
+	go q.run(ctx, j, fn)
+
+	return j.status, nil
+}
+
+func (q *Queue) run(ctx context.Context, j *job, fn Func) {
+	defer q.wg.Done()
+	defer j.cancel()
+
+	select {
+	case q.slots <- struct{}{}:
+		defer func() { <-q.slots }()
+	case <-ctx.Done():
+		q.mu.Lock()
+		q.queued--
+		q.finish(j, nil, ctx.Err())
+		q.mu.Unlock()
+		return
+	}
+
+	q.mu.Lock()
+	q.queued--
+	now := time.Now()
+	j.status.State = Running
+	j.status.Started = &now
+	q.mu.Unlock()
+
+	progress := func(done, total int) {
+		q.mu.Lock()
+		j.status.Done, j.status.Total = done, total
+		if total > 0 {
+			j.status.Progress = float64(done) / float64(total)
+		}
+		q.mu.Unlock()
+	}
+
+	result, err := fn(ctx, progress)
+	if err != nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+
+	q.mu.Lock()
+	q.finish(j, result, err)
+	q.mu.Unlock()
+}
+
+// finish records the outcome, q.mu must be held.
+func (q *Queue) finish(j *job, result any, err error) {
+	now := time.Now()
+	expires := now.Add(q.cfg.TTL)
+	j.status.Finished = &now
+	j.status.Expires = &expires
+
+	switch {
+	case err == nil:
+		j.status.State = Succeeded
+		j.status.Progress = 1
+		j.result = result
+	case errors.Is(err, context.Canceled):
+		j.status.State = Canceled
+	default:
+		j.status.State = Failed
+		j.status.Error = err.Error()
+	}
+}
+
+// expire drops finished jobs past their expiry, q.mu must be held.
+func (q *Queue) expire(now time.Time) {
+	for id, j := range q.jobs {
+		if j.status.Expires != nil && now.After(*j.status.Expires) {
+			delete(q.jobs, id)
+		}
+	}
+}
+
+func (q *Queue) lookup(id string) (*job, error) {
+	q.expire(time.Now())
+	j, ok := q.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return j, nil
+}
+
+func (q *Queue) Status(id string) (Status, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, err := q.lookup(id)
+	if err != nil {
+		return Status{}, err
+	}
+	return j.status, nil
+}
+
+// Result returns the result of a succeeded job. The result is nil for jobs in
+// any other state.
+func (q *Queue) Result(id string) (any, Status, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, err := q.lookup(id)
+	if err != nil {
+		return nil, Status{}, err
+	}
+	return j.result, j.status, nil
+}
+
+// Cancel stops a queued or running job. Running jobs turn canceled once their
+// Func returns, cancelling a finished job does nothing.
+func (q *Queue) Cancel(id string) (Status, error) {
+	q.mu.Lock()
+	j, err := q.lookup(id)
+	q.mu.Unlock()
+	if err != nil {
+		return Status{}, err
+	}
+
+	j.cancel()
+	return q.Status(id)
+}
+
+// Close cancels every job and waits for them to return.
+func (q *Queue) Close() {
+	q.stop()
+	q.wg.Wait()
+}
+
+func newID() (string, error) {
+	var b [12]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
@@ -1,10 +1,21 @@
 package main
 
 import (
+	"fmt"
+	"heat-transfer/cli"
 	"heat-transfer/gui"
+	"os"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		if err := cli.Run(os.Args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	gui.StartGUILoop()
 
 	<-make(chan struct{})
@@ -0,0 +1,350 @@
+// Package quality checks weather time series before they are simulated. It
+// finds gaps, outliers and values in the wrong unit, fills short gaps and
+// refuses data that would only produce nonsense.
+package quality
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ErrUnusable is wrapped by every error for data that cannot be simulated.
+var ErrUnusable = errors.New("unusable weather data")
+
+// Fill is how gaps up to Config.MaxGap are closed.
+type Fill string
+
+const (
+	// FillInterpolate leaves gaps to the interpolation method, no samples
+	// are added.
+	FillInterpolate Fill = "interpolate"
+	// FillLinear adds samples on a straight line across the gap.
+	FillLinear Fill = "linear"
+	// FillPrevious repeats the last sample before the gap.
+	FillPrevious Fill = "previous"
+	// FillNone refuses data with any gap.
+	FillNone Fill = "none"
+)
+
+var Fills = []Fill{FillInterpolate, FillLinear, FillPrevious, FillNone}
+
+func ParseFill(s string) (Fill, error) {
+	for _, f := range Fills {
+		if strings.EqualFold(s, string(f)) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown fill %q, use interpolate, linear, previous or none", s)
+}
+
+// Unit of the input temperatures.
+type Unit string
+
+const (
+	UnitAuto       Unit = "auto" // °C unless the values only make sense in °F or K
+	UnitCelsius    Unit = "C"
+	UnitFahrenheit Unit = "F"
+	UnitKelvin     Unit = "K"
+)
+
+func ParseUnit(s string) (Unit, error) {
+	for _, u := range []Unit{UnitAuto, UnitCelsius, UnitFahrenheit, UnitKelvin} {
+		if strings.EqualFold(s, string(u)) {
+			return u, nil
+		}
+	}
+	return "", fmt.Errorf("unknown unit %q, use auto, C, F or K", s)
+}
+
+// Config of the checks. Times are in minutes, usually after 05:00.
+type Config struct {
+	Start float64 `json:"start"` // first minute the series must cover
+	End   float64 `json:"end"`   // last minute the series must cover
+	Step  float64 `json:"step"`  // expected spacing of the samples
+
+	Unit Unit `json:"unit"`
+	// plausible air temperatures in °C, values outside are removed
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+	// a sample further than this from both neighbours, per hour between
+	// them, is a spike and removed
+	MaxJump float64 `json:"max_jump"` // K
+
+	Fill Fill `json:"fill"`
+	// longest span without samples that is filled, longer ones are refused
+	MaxGap float64 `json:"max_gap"`
+	// fewest samples left after removing outliers
+	MinSamples int `json:"min_samples"`
+}
+
+// DefaultConfig covers the simulated day from 05:00 to 19:00 with hourly
+// samples.
+func DefaultConfig() Config {
+	return Config{
+		Start:      0,
+		End:        840,
+		Step:       60,
+		Unit:       UnitAuto,
+		Min:        -60,
+		Max:        60,
+		MaxJump:    10,
+		Fill:       FillInterpolate,
+		MaxGap:     180,
+		MinSamples: 2,
+	}
+}
+
+// Default is used by the weather sources.
+var Default = DefaultConfig()
+
+// Kind of an issue.
+type Kind string
+
+const (
+	KindUnit      Kind = "unit"
+	KindInvalid   Kind = "invalid" // NaN, infinite or a repeated timestamp
+	KindOutlier   Kind = "outlier"
+	KindGap       Kind = "gap"
+	KindFilled    Kind = "filled"
+	KindUnordered Kind = "unordered"
+)
+
+// Issue is one finding, and what was done about it.
+type Issue struct {
+	Kind    Kind    `json:"kind"`
+	Minute  float64 `json:"minute"`
+	Value   float64 `json:"value,omitempty"`
+	Message string  `json:"message"`
+}
+
+// Report lists what Check found and changed.
+type Report struct {
+	Samples int     `json:"samples"` // in the input
+	Removed int     `json:"removed"`
+	Filled  int     `json:"filled"`
+	Unit    Unit    `json:"unit"` // of the input
+	Issues  []Issue `json:"issues,omitempty"`
+}
+
+func (r *Report) add(kind Kind, minute, value float64, format string, args ...any) {
+	r.Issues = append(r.Issues, Issue{Kind: kind, Minute: minute, Value: value, Message: fmt.Sprintf(format, args...)})
+}
+
+// WriteText prints one line per issue in time order with the time of day.
+func (r Report) WriteText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "%d samples, %d removed, %d filled\n", r.Samples, r.Removed, r.Filled); err != nil {
+		return err
+	}
+	issues := slices.Clone(r.Issues)
+	slices.SortStableFunc(issues, func(a, b Issue) int { return cmp.Compare(a.Minute, b.Minute) })
+	for _, is := range issues {
+		if _, err := fmt.Fprintf(w, "  %s %-9s %s\n", clock(is.Minute), is.Kind, is.Message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clock formats minutes after 05:00 as a time of day.
+func clock(minute float64) string {
+	m := int(math.Round(minute)) + 5*60
+	return fmt.Sprintf("%02d:%02d", m/60, m%60)
+}
+
+// Check validates temperatures taken at minutes and returns the cleaned
+// series. In order it sorts the samples, drops invalid ones, converts the
+// unit, removes outliers and closes gaps with cfg.Fill. The error wraps
+// ErrUnusable when too little data is left or a gap is longer than
+// cfg.MaxGap; the report is returned either way.
+func Check(minutes, values []float64, cfg Config) ([]float64, []float64, Report, error) {
+	r := Report{Samples: len(values), Unit: cfg.Unit}
+	if len(minutes) != len(values) {
+		return nil, nil, r, fmt.Errorf("%w: %d timestamps for %d values", ErrUnusable, len(minutes), len(values))
+	}
+	if len(values) == 0 {
+		return nil, nil, r, fmt.Errorf("%w: no values", ErrUnusable)
+	}
+
+	type sample struct{ minute, value float64 }
+	samples := make([]sample, len(values))
+	for i := range values {
+		samples[i] = sample{minutes[i], values[i]}
+	}
+	if !sort.SliceIsSorted(samples, func(i, j int) bool { return samples[i].minute < samples[j].minute }) {
+		r.add(KindUnordered, samples[0].minute, 0, "samples were not in time order, sorted")
+		sort.SliceStable(samples, func(i, j int) bool { return samples[i].minute < samples[j].minute })
+	}
+
+	valid := samples[:0]
+	for _, s := range samples {
+		switch {
+		case math.IsNaN(s.value) || math.IsInf(s.value, 0) || math.IsNaN(s.minute) || math.IsInf(s.minute, 0):
+			r.add(KindInvalid, s.minute, 0, "missing value removed")
+		case len(valid) > 0 && s.minute == valid[len(valid)-1].minute:
+			// against the last kept sample, a missing value followed by a
+			// valid one at the same minute keeps the valid one
+			r.add(KindInvalid, s.minute, s.value, "repeated timestamp, the first sample is kept")
+		default:
+			valid = append(valid, s)
+			continue
+		}
+		r.Removed++
+	}
+	samples = valid
+	if len(samples) == 0 {
+		return nil, nil, r, fmt.Errorf("%w: no valid values", ErrUnusable)
+	}
+
+	// unit
+	unit := cfg.Unit
+	if unit == UnitAuto || unit == "" {
+		vs := make([]float64, len(samples))
+		for i, s := range samples {
+			vs[i] = s.value
+		}
+		unit = guessUnit(vs)
+		if unit != UnitCelsius {
+			r.add(KindUnit, samples[0].minute, 0, "values look like °%s, converted to °C", unit)
+		}
+	}
+	r.Unit = unit
+	for i := range samples {
+		samples[i].value = toCelsius(samples[i].value, unit)
+	}
+
+	// outliers, implausible values first so they do not hide spikes
+	kept := samples[:0]
+	for _, s := range samples {
+		if s.value < cfg.Min || s.value > cfg.Max {
+			r.add(KindOutlier, s.minute, s.value, "%.1f °C is outside %g to %g °C, removed", s.value, cfg.Min, cfg.Max)
+			r.Removed++
+			continue
+		}
+		kept = append(kept, s)
+	}
+	samples = kept
+	if cfg.MaxJump > 0 && len(samples) >= 3 {
+		spike := make([]bool, len(samples))
+		for i := 1; i < len(samples)-1; i++ {
+			prev, cur, next := samples[i-1], samples[i], samples[i+1]
+			up, down := cur.value-prev.value, cur.value-next.value
+			limit := func(dt float64) float64 { return cfg.MaxJump * math.Max(1, dt/60) }
+			if up*down > 0 && math.Abs(up) > limit(cur.minute-prev.minute) && math.Abs(down) > limit(next.minute-cur.minute) {
+				spike[i] = true
+			}
+		}
+		kept := samples[:0]
+		for i, s := range samples {
+			if spike[i] {
+				r.add(KindOutlier, s.minute, s.value, "%.1f °C spike against its neighbours, removed", s.value)
+				r.Removed++
+				continue
+			}
+			kept = append(kept, s)
+		}
+		samples = kept
+	}
+
+	if len(samples) < max(cfg.MinSamples, 1) {
+		return nil, nil, r, fmt.Errorf("%w: %d of %d values left, at least %d needed", ErrUnusable, len(samples), r.Samples, cfg.MinSamples)
+	}
+
+	// gaps, including the start and end of the span
+	out := make([]sample, 0, len(samples))
+	var refused []string
+	gap := func(from, to float64, before, after *sample) {
+		missing := to - from
+		if before != nil && after != nil {
+			missing -= cfg.Step
+		}
+		if missing < cfg.Step/2 {
+			return
+		}
+		msg := fmt.Sprintf("no data between %s and %s", clock(from), clock(to))
+		if cfg.Fill == FillNone || missing > cfg.MaxGap {
+			r.add(KindGap, from, 0, "%s, refused", msg)
+			refused = append(refused, msg)
+			return
+		}
+		if before == nil || after == nil {
+			// the interpolation holds the first and last values
+			r.add(KindGap, from, 0, "%s, the nearest value is held", msg)
+			return
+		}
+		if cfg.Fill == FillInterpolate {
+			r.add(KindGap, from, 0, "%s, bridged by the interpolation", msg)
+			return
+		}
+		added := 0
+		for m := before.minute + cfg.Step; m < after.minute-cfg.Step/2; m += cfg.Step {
+			v := before.value
+			if cfg.Fill == FillLinear {
+				v += (after.value - before.value) * (m - before.minute) / (after.minute - before.minute)
+			}
+			out = append(out, sample{m, v})
+			added++
+		}
+		r.Filled += added
+		r.add(KindFilled, from, 0, "%s, filled (%s, %d added)", msg, cfg.Fill, added)
+	}
+
+	if cfg.Step > 0 {
+		gap(cfg.Start, samples[0].minute, nil, &samples[0])
+	}
+	for i, s := range samples {
+		if i > 0 && cfg.Step > 0 {
+			gap(samples[i-1].minute, s.minute, &samples[i-1], &samples[i])
+		}
+		out = append(out, s)
+	}
+	if cfg.Step > 0 {
+		last := samples[len(samples)-1]
+		gap(last.minute, cfg.End, &last, nil)
+	}
+	if len(refused) > 0 {
+		limit := fmt.Sprintf("gaps up to %g minutes are filled", cfg.MaxGap)
+		if cfg.Fill == FillNone {
+			limit = "gaps are not filled"
+		}
+		return nil, nil, r, fmt.Errorf("%w: %s; %s", ErrUnusable, strings.Join(refused, ", "), limit)
+	}
+
+	minutes, values = make([]float64, len(out)), make([]float64, len(out))
+	for i, s := range out {
+		minutes[i], values[i] = s.minute, s.value
+	}
+	return minutes, values, r, nil
+}
+
+// guessUnit picks the unit from the median. No air temperature reaches 60 °C
+// and none is below 150 K, so medians from 60 to 150 are °F and above are K.
+func guessUnit(values []float64) Unit {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	median := sorted[len(sorted)/2]
+	switch {
+	case median > 150:
+		return UnitKelvin
+	case median > 60:
+		return UnitFahrenheit
+	default:
+		return UnitCelsius
+	}
+}
+
+func toCelsius(v float64, u Unit) float64 {
+	switch u {
+	case UnitFahrenheit:
+		return (v - 32) * 5 / 9
+	case UnitKelvin:
+		return v - 273.15
+	default:
+		return v
+	}
+}
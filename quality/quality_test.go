@@ -0,0 +1,168 @@
+package quality
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+// hourly returns the minutes of n samples step apart from 05:00.
+func hourly(n int, step float64) []float64 {
+	minutes := make([]float64, n)
+	for i := range minutes {
+		minutes[i] = float64(i) * step
+	}
+	return minutes
+}
+
+func TestCheckFill(t *testing.T) {
+	// 05:00 to 19:00 hourly with 08:00 to 10:00 missing
+	var minutes, values []float64
+	for i, m := range hourly(15, 60) {
+		if i >= 3 && i <= 5 {
+			continue
+		}
+		minutes = append(minutes, m)
+		values = append(values, 25+float64(i))
+	}
+
+	tests := []struct {
+		fill    Fill
+		samples int
+		filled  int
+		at240   float64 // 09:00, NaN when not added
+	}{
+		{FillInterpolate, 12, 0, math.NaN()},
+		{FillLinear, 15, 3, 29},
+		{FillPrevious, 15, 3, 27},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.fill), func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Fill = tt.fill
+			m, v, r, err := Check(minutes, values, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(m) != tt.samples || r.Filled != tt.filled {
+				t.Fatalf("%d samples, %d filled, want %d and %d", len(m), r.Filled, tt.samples, tt.filled)
+			}
+			i := slices.Index(m, 240)
+			if math.IsNaN(tt.at240) {
+				if i >= 0 {
+					t.Errorf("09:00 was added")
+				}
+				return
+			}
+			if i < 0 || v[i] != tt.at240 {
+				t.Errorf("09:00 is %v, want %v", v, tt.at240)
+			}
+		})
+	}
+}
+
+func TestCheckRefused(t *testing.T) {
+	tests := []struct {
+		name    string
+		minutes []float64
+		values  []float64
+		cfg     func(*Config)
+	}{
+		{"gap longer than MaxGap", []float64{0, 60, 480, 540, 600, 660, 720, 780, 840}, []float64{25, 26, 30, 31, 32, 31, 30, 29, 28}, nil},
+		{"no gaps allowed", []float64{0, 60, 180, 240}, []float64{25, 26, 27, 28}, func(c *Config) { c.Fill = FillNone; c.End = 240 }},
+		{"every value missing", []float64{0, 60}, []float64{math.NaN(), math.Inf(1)}, nil},
+		{"too few left", []float64{0, 60, 120}, []float64{25, 99, 98}, func(c *Config) { c.End = 120; c.Unit = UnitCelsius; c.MinSamples = 2 }},
+		{"lengths differ", []float64{0, 60}, []float64{25}, nil},
+		{"empty", nil, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			if tt.cfg != nil {
+				tt.cfg(&cfg)
+			}
+			_, _, _, err := Check(tt.minutes, tt.values, cfg)
+			if !errors.Is(err, ErrUnusable) {
+				t.Errorf("error %v, want ErrUnusable", err)
+			}
+		})
+	}
+}
+
+func TestCheckUnit(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		unit   Unit
+		want   float64 // first value in °C
+	}{
+		{"celsius", []float64{25, 30, 35}, UnitCelsius, 25},
+		{"fahrenheit", []float64{77, 86, 95}, UnitFahrenheit, 25},
+		{"kelvin", []float64{298.15, 303.15, 308.15}, UnitKelvin, 25},
+		// a hot day in °C stays °C
+		{"hot celsius", []float64{38, 44, 47}, UnitCelsius, 38},
+		// the median decides, one stray reading does not
+		{"fahrenheit with a zero", []float64{0, 80, 86, 90}, UnitFahrenheit, -17.78},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.End = float64(len(tt.values)-1) * 60
+			cfg.Min = -100
+			_, v, r, err := Check(hourly(len(tt.values), 60), tt.values, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Unit != tt.unit {
+				t.Errorf("unit %s, want %s", r.Unit, tt.unit)
+			}
+			if math.Abs(v[0]-tt.want) > 0.01 {
+				t.Errorf("first value %.2f °C, want %.2f", v[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		minutes []float64
+		values  []float64
+		want    []float64
+		removed int
+	}{
+		{"missing value", []float64{0, 60, 120}, []float64{25, math.NaN(), 27}, []float64{25, 27}, 1},
+		{"repeated timestamp keeps the first", []float64{0, 60, 60, 120}, []float64{25, 26, 40, 27}, []float64{25, 26, 27}, 1},
+		{"missing then valid at the same minute", []float64{0, 60, 60, 120}, []float64{25, math.NaN(), 26, 27}, []float64{25, 26, 27}, 1},
+		{"unordered", []float64{120, 0, 60}, []float64{27, 25, 26}, []float64{25, 26, 27}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.End = 120
+			cfg.Unit = UnitCelsius
+			_, v, r, err := Check(tt.minutes, tt.values, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(v, tt.want) || r.Removed != tt.removed {
+				t.Errorf("values %v with %d removed, want %v and %d", v, r.Removed, tt.want, tt.removed)
+			}
+		})
+	}
+}
+
+func TestCheckOutliers(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.End = 360
+	cfg.Unit = UnitCelsius
+	// 09:00 is a spike, 11:00 is out of range
+	_, v, r, err := Check(hourly(7, 60), []float64{25, 26, 27, 28, 45, 30, 75}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(v, []float64{25, 26, 27, 28, 30}) || r.Removed != 2 {
+		t.Errorf("values %v with %d removed, want the spike and the implausible value gone", v, r.Removed)
+	}
+}
@@ -0,0 +1,229 @@
+package report
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/font"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgpdf"
+)
+
+// A4 portrait
+const (
+	pageWidth  = 210 * vg.Millimeter
+	pageHeight = 297 * vg.Millimeter
+	margin     = 18 * vg.Millimeter
+)
+
+var (
+	grey      = color.Gray{Y: 110}
+	lightGrey = color.Gray{Y: 225}
+)
+
+// document lays out text, tables and plots top to bottom on vector PDF pages.
+type document struct {
+	pdf    *vgpdf.Canvas
+	dc     draw.Canvas
+	y      vg.Length // top of the free space on the current page
+	page   int
+	footer string
+}
+
+func newDocument(footer string) *document {
+	pdf := vgpdf.New(pageWidth, pageHeight)
+	d := &document{
+		pdf:    pdf,
+		dc:     draw.New(pdf),
+		y:      pageHeight - margin,
+		page:   1,
+		footer: footer,
+	}
+	return d
+}
+
+func (d *document) style(size vg.Length, variant font.Variant, c color.Color) draw.TextStyle {
+	return draw.TextStyle{
+		Color:   c,
+		Font:    font.Font{Typeface: "Liberation", Variant: variant, Size: size},
+		Handler: plot.DefaultTextHandler,
+		XAlign:  draw.XLeft,
+		YAlign:  draw.YTop,
+	}
+}
+
+// ensure starts a new page when less than h is left on this one.
+func (d *document) ensure(h vg.Length) {
+	if d.y-h < margin+8*vg.Millimeter {
+		d.newPage()
+	}
+}
+
+func (d *document) newPage() {
+	d.drawFooter()
+	d.pdf.NextPage()
+	d.page++
+	d.y = pageHeight - margin
+}
+
+func (d *document) drawFooter() {
+	sty := d.style(8, "Sans", grey)
+	d.dc.FillText(sty, vg.Point{X: margin, Y: margin}, d.footer)
+
+	sty.XAlign = draw.XRight
+	d.dc.FillText(sty, vg.Point{X: pageWidth - margin, Y: margin}, fmt.Sprintf("Page %d", d.page))
+}
+
+func (d *document) title(s string) {
+	d.ensure(14 * vg.Millimeter)
+	d.dc.FillText(d.style(20, "Sans", color.Black), vg.Point{X: margin, Y: d.y}, s)
+	d.y -= 12 * vg.Millimeter
+}
+
+func (d *document) heading(s string) {
+	d.ensure(20 * vg.Millimeter)
+	d.y -= 3 * vg.Millimeter
+	d.dc.FillText(d.style(13, "Sans", color.Black), vg.Point{X: margin, Y: d.y}, s)
+	d.y -= 6 * vg.Millimeter
+	d.dc.StrokeLine2(draw.LineStyle{Color: lightGrey, Width: 0.8}, margin, d.y, pageWidth-margin, d.y)
+	d.y -= 3 * vg.Millimeter
+}
+
+func (d *document) paragraph(s string) {
+	sty := d.style(10, "Serif", color.Black)
+	for _, line := range wrap(sty, s, pageWidth-2*margin) {
+		d.ensure(5 * vg.Millimeter)
+		d.dc.FillText(sty, vg.Point{X: margin, Y: d.y}, line)
+		d.y -= 5 * vg.Millimeter
+	}
+	d.y -= 2 * vg.Millimeter
+}
+
+func (d *document) bullets(items []string) {
+	sty := d.style(10, "Serif", color.Black)
+	indent := 5 * vg.Millimeter
+	for _, item := range items {
+		for i, line := range wrap(sty, item, pageWidth-2*margin-indent) {
+			d.ensure(5 * vg.Millimeter)
+			if i == 0 {
+				d.dc.FillText(sty, vg.Point{X: margin, Y: d.y}, "•")
+			}
+			d.dc.FillText(sty, vg.Point{X: margin + indent, Y: d.y}, line)
+			d.y -= 5 * vg.Millimeter
+		}
+	}
+	d.y -= 2 * vg.Millimeter
+}
+
+// keyValues prints a two column list of labels and values.
+func (d *document) keyValues(pairs [][2]string) {
+	label := d.style(10, "Sans", grey)
+	value := d.style(10, "Serif", color.Black)
+	for _, kv := range pairs {
+		d.ensure(5 * vg.Millimeter)
+		d.dc.FillText(label, vg.Point{X: margin, Y: d.y}, kv[0])
+		d.dc.FillText(value, vg.Point{X: margin + 60*vg.Millimeter, Y: d.y}, kv[1])
+		d.y -= 5 * vg.Millimeter
+	}
+	d.y -= 2 * vg.Millimeter
+}
+
+// table prints a header row and rows; widths are fractions of the text width.
+// Columns after the first are right aligned.
+func (d *document) table(header []string, widths []float64, rows [][]string) {
+	total := pageWidth - 2*margin
+	head := d.style(9, "Sans", color.Black)
+	body := d.style(9, "Serif", color.Black)
+
+	drawRow := func(sty draw.TextStyle, cells []string) {
+		x := margin
+		for i, cell := range cells {
+			w := vg.Length(widths[i]) * total
+			s := sty
+			pt := vg.Point{X: x, Y: d.y}
+			if i > 0 {
+				s.XAlign = draw.XRight
+				pt.X = x + w - 1*vg.Millimeter
+			}
+			d.dc.FillText(s, pt, cell)
+			x += w
+		}
+		d.y -= 4.5 * vg.Millimeter
+	}
+
+	d.ensure(10 * vg.Millimeter)
+	drawRow(head, header)
+	d.dc.StrokeLine2(draw.LineStyle{Color: lightGrey, Width: 0.6}, margin, d.y+1*vg.Millimeter, pageWidth-margin, d.y+1*vg.Millimeter)
+	for _, r := range rows {
+		if d.y-4.5*vg.Millimeter < margin+8*vg.Millimeter {
+			d.newPage()
+			drawRow(head, header)
+		}
+		drawRow(body, r)
+	}
+	d.y -= 3 * vg.Millimeter
+}
+
+// chart draws vector graphics across the text width.
+func (d *document) chart(height vg.Length, drawFn func(c draw.Canvas) error) error {
+	d.ensure(height + 2*vg.Millimeter)
+	rect := vg.Rectangle{
+		Min: vg.Point{X: margin, Y: d.y - height},
+		Max: vg.Point{X: pageWidth - margin, Y: d.y},
+	}
+	if err := drawFn(draw.Canvas{Canvas: d.pdf, Rectangle: rect}); err != nil {
+		return err
+	}
+	d.y -= height + 4*vg.Millimeter
+	return nil
+}
+
+func (d *document) writeTo(w io.Writer) error {
+	d.drawFooter()
+	_, err := d.pdf.WriteTo(w)
+	return err
+}
+
+// wrap breaks s into lines no wider than width.
+func wrap(sty draw.TextStyle, s string, width vg.Length) []string {
+	var lines []string
+	line := ""
+	for _, word := range splitWords(s) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if line != "" && sty.Width(candidate) > width {
+			lines = append(lines, line)
+			line = word
+			continue
+		}
+		line = candidate
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func splitWords(s string) []string {
+	var words []string
+	word := []rune{}
+	for _, r := range s {
+		if r == ' ' || r == '\n' || r == '\t' {
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = word[:0]
+			}
+			continue
+		}
+		word = append(word, r)
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
@@ -0,0 +1,341 @@
+// Package report renders a scenario result as a PDF engineering report.
+package report
+
+import (
+	"fmt"
+	"heat-transfer/calc"
+	"heat-transfer/chartings"
+	"heat-transfer/constants"
+	"heat-transfer/scenario"
+	"io"
+	"time"
+
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// ProjectInfo is printed on the first page of the report.
+type ProjectInfo struct {
+	Title     string    `json:"title"`
+	Project   string    `json:"project"`
+	Client    string    `json:"client"`
+	Location  string    `json:"location"`
+	Engineer  string    `json:"engineer"`
+	Reference string    `json:"reference"`
+	Date      time.Time `json:"date"`
+}
+
+// Write renders r as a multi-page A4 PDF to w. Charts are embedded as vector
+// graphics.
+func Write(w io.Writer, info ProjectInfo, r scenario.Result) error {
+	if len(r.InsideTemps) == 0 {
+		return fmt.Errorf("report: scenario %q has no simulation results", r.Scenario.Name)
+	}
+	if info.Title == "" {
+		info.Title = "Room Heat Transfer Report"
+	}
+	if info.Date.IsZero() {
+		info.Date = time.Now()
+	}
+
+	footer := info.Title
+	if info.Reference != "" {
+		footer += " - " + info.Reference
+	}
+	d := newDocument(footer)
+
+	d.title(info.Title)
+	d.keyValues(projectRows(info, r.Scenario))
+
+	d.heading("1. Envelope")
+	writeEnvelope(d, r)
+
+	d.heading("2. Materials and U-values")
+	writeMaterials(d, r)
+
+	d.heading("3. Temperature profile")
+	if err := writeTemperatures(d, r); err != nil {
+		return err
+	}
+
+	d.heading("4. Heat flow")
+	if err := writeHeatFlow(d, r); err != nil {
+		return err
+	}
+
+	d.heading("5. Air conditioning and electricity cost")
+	if err := writeACCost(d, r); err != nil {
+		return err
+	}
+
+	if len(r.BOQ.Items) > 0 {
+		d.heading("6. Material and installation cost")
+		if err := writeBOQ(d, r.BOQ); err != nil {
+			return err
+		}
+	}
+
+	d.heading("Assumptions")
+	d.bullets(assumptions(r))
+
+	return d.writeTo(w)
+}
+
+func projectRows(info ProjectInfo, s scenario.Scenario) [][2]string {
+	rows := [][2]string{}
+	add := func(label, value string) {
+		if value != "" {
+			rows = append(rows, [2]string{label, value})
+		}
+	}
+	add("Project", info.Project)
+	add("Client", info.Client)
+	add("Location", info.Location)
+	add("Engineer", info.Engineer)
+	add("Reference", info.Reference)
+	add("Date", info.Date.Format("2 January 2006"))
+	add("Scenario", s.Name)
+	return rows
+}
+
+func writeEnvelope(d *document, r scenario.Result) {
+	s := r.Scenario
+	wallArea := 2 * s.Height * (s.Width + s.Depth)
+
+	d.keyValues([][2]string{
+		{"Room (W x D x H)", fmt.Sprintf("%.2f x %.2f x %.2f m", s.Width, s.Depth, s.Height)},
+		{"Floor area", fmt.Sprintf("%.2f m²", s.Width*s.Depth)},
+		{"Volume", fmt.Sprintf("%.2f m³", s.Width*s.Height*s.Depth)},
+		{"Wall area", fmt.Sprintf("%.2f m²", wallArea)},
+		{"Roof insulated", yesNo(s.Roof)},
+		{"Floor insulated", yesNo(s.Floor)},
+		{"Infiltration", fmt.Sprintf("%.2f ACH", s.Infiltration)},
+	})
+
+	if len(s.Layers) == 0 {
+		return
+	}
+	elements := calc.RoomEnvelope(s.Width, s.Height, s.Depth, s.Layers, s.Roof, s.Floor)
+	rows := make([][]string, 0, len(elements))
+	for _, e := range elements {
+		rows = append(rows, []string{
+			e.Name,
+			fmt.Sprintf("%.2f", e.Width),
+			fmt.Sprintf("%.2f", e.Height),
+			fmt.Sprintf("%.2f", e.GrossArea()),
+			fmt.Sprintf("%.2f", e.NetArea()),
+		})
+	}
+	d.table([]string{"Element", "Width (m)", "Height (m)", "Gross (m²)", "Net (m²)"},
+		[]float64{0.4, 0.15, 0.15, 0.15, 0.15}, rows)
+}
+
+func writeMaterials(d *document, r scenario.Result) {
+	s := r.Scenario
+
+	if len(s.Layers) > 0 {
+		rows := make([][]string, 0, len(s.Layers)+1)
+		totalR := 0.0
+		for _, l := range s.Layers {
+			m, err := constants.GetMaterial(l.Material)
+			if err != nil {
+				rows = append(rows, []string{l.Material, fmt.Sprintf("%.0f", l.Thickness*1000), "-", "-", "-", "-"})
+				continue
+			}
+			rValue := l.Thickness / m.Conductivity
+			totalR += rValue
+			rows = append(rows, []string{
+				m.Name,
+				fmt.Sprintf("%.0f", l.Thickness*1000),
+				fmt.Sprintf("%.3f", m.Conductivity),
+				fmt.Sprintf("%.0f", m.Density),
+				fmt.Sprintf("%.0f", m.SpecificHeat),
+				fmt.Sprintf("%.3f", rValue),
+			})
+		}
+		rows = append(rows, []string{"Total", "", "", "", "", fmt.Sprintf("%.3f", totalR)})
+		d.table([]string{"Layer (inside to outside)", "t (mm)", "k (W/m·K)", "ρ (kg/m³)", "cp (J/kg·K)", "R (m²·K/W)"},
+			[]float64{0.35, 0.11, 0.14, 0.13, 0.13, 0.14}, rows)
+	}
+
+	pairs := [][2]string{
+		{"Envelope U-value", fmt.Sprintf("%.3f W/m²·K", r.Coeff)},
+		{"Infiltration equivalent", fmt.Sprintf("%.3f W/m²·K", r.EffCoeff-r.Coeff)},
+		{"Effective U-value", fmt.Sprintf("%.3f W/m²·K", r.EffCoeff)},
+	}
+	if len(s.Layers) > 0 && s.Coeff > 0 {
+		pairs = append(pairs, [2]string{"Note", "U-value overridden by the scenario"})
+	}
+	d.keyValues(pairs)
+}
+
+func writeTemperatures(d *document, r scenario.Result) error {
+	o := chartings.DefaultOptions()
+	height := 95 * vg.Millimeter
+	if ac := r.Scenario.AC; ac != nil && ac.Enabled {
+		o.SetPoint = &ac.SetTemp
+		o.AC = &chartings.ACOverlay{Running: r.ACRunning, OnTime: ac.OnTime, OffTime: ac.OffTime, CoolingPower: ac.CoolingPower}
+		height = 150 * vg.Millimeter
+	}
+	err := d.chart(height, func(c draw.Canvas) error {
+		return chartings.DrawTemperature(c,
+			chartings.XYs(r.TimeMinutes, r.InsideTemps),
+			chartings.XYs(r.TimeMinutes, r.OutsideTemps), o)
+	})
+	if err != nil {
+		return err
+	}
+
+	peakOutside := r.OutsideTemps[0]
+	for _, t := range r.OutsideTemps {
+		if t > peakOutside {
+			peakOutside = t
+		}
+	}
+	d.keyValues([][2]string{
+		{"Simulated period", "05:00 - 19:00"},
+		{"Peak inside", fmt.Sprintf("%.2f °C at %s", r.PeakInsideTemp, clockAt(r, r.PeakInsideTemp))},
+		{"Minimum inside", fmt.Sprintf("%.2f °C", r.MinInsideTemp)},
+		{"Peak outside", fmt.Sprintf("%.2f °C", peakOutside)},
+	})
+	return nil
+}
+
+func writeHeatFlow(d *document, r scenario.Result) error {
+	d.paragraph("Heat gained by the room air through each wall, by air leakage and by the AC, averaged over 15 minutes. Gains are stacked above zero and losses below it; the line is the net gain.")
+
+	o := chartings.DefaultOptions()
+	o.Title = "Heat Flow by Element"
+	x, stacks := chartings.Resample(r.TimeMinutes, chartings.HeatFlowStacks(r), 15)
+	err := d.chart(85*vg.Millimeter, func(c draw.Canvas) error {
+		p, err := chartings.HeatFlowPlot(x, stacks, o)
+		if err != nil {
+			return err
+		}
+		p.Draw(c)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	flows := r.HeatFlows()
+	rows := make([][]string, 0, len(flows))
+	for _, f := range flows {
+		rows = append(rows, []string{f.Name(), fmt.Sprintf("%.2f", f.KWh())})
+	}
+	d.table([]string{"Source", "Net gain (kWh/day)"}, []float64{0.7, 0.3}, rows)
+	return nil
+}
+
+func writeACCost(d *document, r scenario.Result) error {
+	ac := r.Scenario.AC
+	if ac == nil || !ac.Enabled {
+		d.paragraph("No air conditioning is modelled in this scenario.")
+		return nil
+	}
+
+	c := r.ACCost
+	d.keyValues([][2]string{
+		{"Operating window", clock(ac.OnTime) + " - " + clock(ac.OffTime)},
+		{"Set point", fmt.Sprintf("%.1f °C (±1.5 °C band)", ac.SetTemp)},
+		{"Cooling power", fmt.Sprintf("%.0f W", -ac.CoolingPower)},
+		{"Compressor runtime", fmt.Sprintf("%d min/day (%.1f h)", c.ACMinutes, float64(c.ACMinutes)/60)},
+		{"AC energy", fmt.Sprintf("%.2f kWh/day, %.1f kWh/month", c.DailyKWh, c.MonthlyKWh)},
+		{"Other household use", fmt.Sprintf("%.1f kWh/month", r.Scenario.ExistingUsage)},
+	})
+
+	rows := [][]string{
+		{"Energy charge (AC share)", formatTHB(c.EnergyCharge)},
+		{"Ft charge", formatTHB(c.FtCharge)},
+		{"Service charge (AC share)", formatTHB(c.ServiceCharge)},
+		{"VAT", formatTHB(c.VAT)},
+		{"Total per month", formatTHB(c.Total)},
+		{"Total per year", formatTHB(c.Total * 12)},
+	}
+	d.table([]string{fmt.Sprintf("Electricity cost (%d day month)", c.DaysInMonth), "THB"}, []float64{0.7, 0.3}, rows)
+
+	return costChart(d, "Monthly AC Electricity Cost", chartings.ACCostBars(c), "THB/month")
+}
+
+// costChart draws a bar chart of bars across the text width.
+func costChart(d *document, title string, bars []chartings.Bar, unit string) error {
+	o := chartings.DefaultOptions()
+	o.Title = title
+	return d.chart(70*vg.Millimeter, func(c draw.Canvas) error {
+		p, err := chartings.CostPlot(bars, unit, o)
+		if err != nil {
+			return err
+		}
+		p.Draw(c)
+		return nil
+	})
+}
+
+func writeBOQ(d *document, boq calc.BillOfQuantities) error {
+	rows := make([][]string, 0, len(boq.Items)+3)
+	for _, it := range boq.Items {
+		rows = append(rows, []string{
+			it.Element + " - " + it.Material,
+			fmt.Sprintf("%.2f", it.NetArea),
+			fmt.Sprintf("%.3f", it.OrderVolume),
+			fmt.Sprintf("%.0f", it.MaterialCost.Typical),
+			fmt.Sprintf("%.0f", it.LabourCost.Typical),
+			fmt.Sprintf("%.0f", it.Total.Typical),
+		})
+	}
+	d.table([]string{"Item", "Net (m²)", "Order (m³)", "Material", "Labour", "Total (THB)"},
+		[]float64{0.35, 0.12, 0.13, 0.13, 0.13, 0.14}, rows)
+
+	d.keyValues([][2]string{
+		{"Material", boq.MaterialTotal.String()},
+		{"Labour", boq.LabourTotal.String()},
+		{"Total", boq.Total.String()},
+	})
+
+	return costChart(d, "Material and Installation Cost", chartings.BOQCostBars(boq), "THB")
+}
+
+func assumptions(r scenario.Result) []string {
+	p := calc.DefaultTakeoffParams()
+	list := []string{
+		"The room air is modelled as a single well mixed volume at sea level pressure (cp = 1005 J/kg·K).",
+		"Heat transfer uses the effective U-value over the wall area only; solar gain, internal gains and thermal mass of the envelope are not included.",
+		"The temperature equation is integrated with fourth-order Runge-Kutta using a 10 s step; results are reported every minute.",
+		"The AC switches with a ±1.5 °C hysteresis around the set point and only draws power inside its operating window.",
+		"Electricity cost uses the 2024 Thai residential progressive tariff, Ft and 7% VAT over a 30 day month. Energy and service charges are apportioned to the AC by its share of the monthly consumption.",
+		"Layer U-values are conduction only; surface film resistances are neglected.",
+	}
+	if len(r.BOQ.Items) > 0 {
+		list = append(list, fmt.Sprintf("Material quantities include %.0f%% waste and corner overlap; labour is priced at %s per m² per layer.",
+			p.WasteFactor*100, p.LabourPerM2.String()))
+	}
+	return list
+}
+
+// clockAt returns the first time of day at which the inside temperature is t.
+func clockAt(r scenario.Result, t float64) string {
+	for i, v := range r.InsideTemps {
+		if v == t {
+			return clock(int(r.TimeMinutes[i]))
+		}
+	}
+	return "-"
+}
+
+// clock formats minutes after 05:00 as HH:MM.
+func clock(minutes int) string {
+	minutes += 5 * 60
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+func formatTHB(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
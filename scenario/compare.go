@@ -0,0 +1,97 @@
+package scenario
+
+import (
+	"errors"
+	"fmt"
+	"heat-transfer/finance"
+	"io"
+	"text/tabwriter"
+)
+
+// Comparison holds the results of several scenarios run against the same
+// weather. The first scenario is the baseline every delta is measured from.
+type Comparison struct {
+	Results []Result         `json:"results"`
+	Deltas  []Delta          `json:"deltas"`
+	Finance []finance.Report `json:"finance"`
+}
+
+type Delta struct {
+	Name           string  `json:"name"`
+	PeakInsideTemp float64 `json:"peak_inside_temp"` // °C
+	ACMinutes      int     `json:"ac_minutes"`
+	DailyKWh       float64 `json:"daily_kwh"`
+	MonthlyACCost  float64 `json:"monthly_ac_cost"` // THB
+	MaterialCost   float64 `json:"material_cost"`   // THB, typical
+}
+
+func Compare(scenarios []Scenario, outsideTemps [840]float64, assumptions finance.Assumptions) (Comparison, error) {
+	if len(scenarios) == 0 {
+		return Comparison{}, errors.New("no scenarios to compare")
+	}
+
+	var c Comparison
+	for i, s := range scenarios {
+		if s.Name == "" {
+			s.Name = fmt.Sprintf("Scenario %d", i+1)
+		}
+		r, err := Run(s, outsideTemps)
+		if err != nil {
+			return Comparison{}, err
+		}
+		c.Results = append(c.Results, r)
+	}
+
+	base := c.Results[0]
+	baseline := financeScenario(base)
+	for _, r := range c.Results {
+		c.Deltas = append(c.Deltas, Delta{
+			Name:           r.Scenario.Name,
+			PeakInsideTemp: r.PeakInsideTemp - base.PeakInsideTemp,
+			ACMinutes:      r.ACMinutes - base.ACMinutes,
+			DailyKWh:       r.DailyKWh - base.DailyKWh,
+			MonthlyACCost:  r.MonthlyACCost - base.MonthlyACCost,
+			MaterialCost:   r.MaterialCost.Typical - base.MaterialCost.Typical,
+		})
+	}
+
+	for _, r := range c.Results[1:] {
+		report, err := finance.Analyze(baseline, financeScenario(r), assumptions)
+		if err != nil {
+			return Comparison{}, err
+		}
+		c.Finance = append(c.Finance, report)
+	}
+
+	return c, nil
+}
+
+func financeScenario(r Result) finance.Scenario {
+	return finance.Scenario{
+		Name:             r.Scenario.Name,
+		InitialCost:      r.MaterialCost.Typical,
+		AnnualEnergyCost: r.MonthlyACCost * 12,
+	}
+}
+
+// WriteTable writes a plain text comparison table.
+func (c Comparison) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Scenario\tU (W/m²K)\tPeak °C\tΔ Peak\tAC min\tΔ AC min\tkWh/day\tAC THB/month\tΔ THB/month\tMaterial THB\tΔ Material\t")
+	for i, r := range c.Results {
+		d := c.Deltas[i]
+		fmt.Fprintf(tw, "%s\t%.3f\t%.1f\t%+.1f\t%d\t%+d\t%.2f\t%.2f\t%+.2f\t%.0f\t%+.0f\t\n",
+			r.Scenario.Name, r.Coeff, r.PeakInsideTemp, d.PeakInsideTemp, r.ACMinutes, d.ACMinutes,
+			r.DailyKWh, r.MonthlyACCost, d.MonthlyACCost, r.MaterialCost.Typical, d.MaterialCost)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, f := range c.Finance {
+		if _, err := fmt.Fprintln(w, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
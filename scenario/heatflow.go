@@ -0,0 +1,85 @@
+package scenario
+
+import (
+	"fmt"
+	"heat-transfer/calc"
+)
+
+const (
+	LoadConduction   = "conduction"
+	LoadInfiltration = "infiltration"
+	LoadAC           = "ac"
+)
+
+// HeatFlow is the heat gained by the room air through one element and load
+// type at every recorded minute, in W. Losses are negative.
+type HeatFlow struct {
+	Element string    `json:"element"`
+	Load    string    `json:"load"`
+	Watts   []float64 `json:"watts"`
+}
+
+func (f HeatFlow) Name() string {
+	switch f.Load {
+	case LoadConduction:
+		return fmt.Sprintf("%s conduction", f.Element)
+	case LoadAC:
+		return "AC cooling"
+	default:
+		return f.Element
+	}
+}
+
+// KWh returns the net heat gained over the simulated day.
+func (f HeatFlow) KWh() float64 {
+	sum := 0.0
+	for _, w := range f.Watts {
+		sum += w
+	}
+	// one sample per minute
+	return sum * 60 / 3.6e6
+}
+
+// UA is the conductance of the walls and infiltration in W/K, over the same
+// wall area as the solver.
+func (r Result) UA() float64 {
+	s := r.Scenario
+	return r.EffCoeff * 2 * s.Height * (s.Width + s.Depth)
+}
+
+// HeatFlows splits the heat balance of the simulation by wall, infiltration
+// and AC, using the same wall-area model as calc.CalculateTemperatureProfile.
+func (r Result) HeatFlows() []HeatFlow {
+	s := r.Scenario
+	n := min(len(r.InsideTemps), len(r.OutsideTemps))
+
+	var flows []HeatFlow
+	for _, e := range calc.RoomEnvelope(s.Width, s.Height, s.Depth, nil, false, false) {
+		ua := r.Coeff * e.GrossArea()
+		flow := HeatFlow{Element: e.Name, Load: LoadConduction, Watts: make([]float64, n)}
+		for i := range n {
+			flow.Watts[i] = ua * (r.OutsideTemps[i] - r.InsideTemps[i])
+		}
+		flows = append(flows, flow)
+	}
+
+	if ua := (r.EffCoeff - r.Coeff) * 2 * s.Height * (s.Width + s.Depth); ua > 0 {
+		flow := HeatFlow{Element: "Infiltration", Load: LoadInfiltration, Watts: make([]float64, n)}
+		for i := range n {
+			flow.Watts[i] = ua * (r.OutsideTemps[i] - r.InsideTemps[i])
+		}
+		flows = append(flows, flow)
+	}
+
+	if s.AC != nil && s.AC.Enabled {
+		flow := HeatFlow{Element: "AC", Load: LoadAC, Watts: make([]float64, n)}
+		for i := range n {
+			if r.Cooling(i) {
+				flow.Watts[i] = s.AC.CoolingPower
+			}
+		}
+		flows = append(flows, flow)
+	}
+
+	return flows
+}
@@ -0,0 +1,212 @@
+package scenario
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"heat-transfer/calc"
+	"heat-transfer/constants"
+	"math"
+	"os"
+)
+
+// Scenario is one complete room configuration that can be simulated.
+type Scenario struct {
+	Name   string  `json:"name"`
+	Width  float64 `json:"width"`  // m
+	Height float64 `json:"height"` // m
+	Depth  float64 `json:"depth"`  // m
+
+	Layers []calc.Layer `json:"layers,omitempty"`
+	// U value in W/(m²·K), overrides the value derived from the layers
+	Coeff float64 `json:"coeff,omitempty"`
+	// outside air exchange in air changes per hour
+	Infiltration float64 `json:"infiltration,omitempty"`
+
+	Roof  bool `json:"roof"`
+	Floor bool `json:"floor"`
+
+	// defaults to the first outside temperature
+	InsideTemp *float64 `json:"inside_temp,omitempty"`
+
+	AC            *calc.ACParams `json:"ac,omitempty"`
+	ExistingUsage float64        `json:"existing_usage"` // kWh per month without AC
+}
+
+type Result struct {
+	Scenario Scenario `json:"scenario"`
+	Coeff    float64  `json:"coeff"`           // envelope U value
+	EffCoeff float64  `json:"effective_coeff"` // including infiltration
+
+	TimeMinutes  []float64 `json:"time_minutes"`
+	InsideTemps  []float64 `json:"inside_temps"`
+	OutsideTemps []float64 `json:"outside_temps"`
+	ACRunning    []bool    `json:"ac_running"`
+
+	PeakInsideTemp float64 `json:"peak_inside_temp"`
+	MinInsideTemp  float64 `json:"min_inside_temp"`
+	ACMinutes      int     `json:"ac_minutes"`
+	DailyKWh       float64 `json:"daily_kwh"`
+	MonthlyACCost  float64 `json:"monthly_ac_cost"` // THB
+
+	ACCost calc.ACCostBreakdown `json:"ac_cost"`
+
+	BOQ          calc.BillOfQuantities `json:"boq"`
+	MaterialCost constants.PriceRange  `json:"material_cost"`
+
+	// outside conditions beyond temperature, when the source had them
+	Weather *calc.Weather `json:"weather,omitempty"`
+}
+
+func (s Scenario) Validate() error {
+	if s.Width <= 0 || s.Height <= 0 || s.Depth <= 0 {
+		return fmt.Errorf("scenario %q: room dimensions must be greater than zero", s.Name)
+	}
+	if len(s.Layers) == 0 && s.Coeff <= 0 {
+		return fmt.Errorf("scenario %q: layers or a coefficient are required", s.Name)
+	}
+	if s.Infiltration < 0 {
+		return fmt.Errorf("scenario %q: infiltration must not be negative", s.Name)
+	}
+	if s.AC != nil && s.AC.Enabled {
+		if s.AC.OnTime < 0 || s.AC.OffTime > 840 || s.AC.OnTime > s.AC.OffTime {
+			return fmt.Errorf("scenario %q: AC schedule must lie within 05:00 - 19:00", s.Name)
+		}
+	}
+	return nil
+}
+
+// Run simulates the scenario against a day of outside temperatures.
+func Run(s Scenario, outsideTemps [840]float64) (Result, error) {
+	return RunContext(context.Background(), s, outsideTemps, nil)
+}
+
+// RunWeather is Run with the full outside conditions. They are kept on the
+// result for models that need more than the temperature.
+func RunWeather(s Scenario, w calc.Weather) (Result, error) {
+	r, err := Run(s, w.Temp)
+	if err != nil {
+		return Result{}, err
+	}
+	if w.Extended {
+		r.Weather = &w
+	}
+	return r, nil
+}
+
+// RunContext is Run with cancellation and progress reporting, see
+// calc.CalculateTemperatureProfileContext.
+func RunContext(ctx context.Context, s Scenario, outsideTemps [840]float64, progress func(done, total int)) (Result, error) {
+	if err := s.Validate(); err != nil {
+		return Result{}, err
+	}
+	for i, t := range outsideTemps {
+		if math.IsNaN(t) || math.IsInf(t, 0) {
+			return Result{}, fmt.Errorf("scenario %q: outside temperature at minute %d is %v, check the weather data", s.Name, i, t)
+		}
+	}
+
+	r := Result{Scenario: s, Coeff: s.Coeff}
+
+	if len(s.Layers) > 0 {
+		if s.Coeff <= 0 {
+			coeff, err := calc.CalculateCoeffByLayers(s.Layers)
+			if err != nil {
+				return Result{}, fmt.Errorf("scenario %q: %w", s.Name, err)
+			}
+			r.Coeff = coeff
+		}
+
+		envelope := calc.RoomEnvelope(s.Width, s.Height, s.Depth, s.Layers, s.Roof, s.Floor)
+		boq, err := calc.CalculateTakeoff(envelope, calc.DefaultTakeoffParams())
+		if err != nil {
+			return Result{}, fmt.Errorf("scenario %q: %w", s.Name, err)
+		}
+		r.BOQ = boq
+		r.MaterialCost = boq.Total
+	} else {
+		r.MaterialCost = constants.PriceRange{Currency: constants.BaseCurrency}
+	}
+
+	insideTemp := outsideTemps[0]
+	if s.InsideTemp != nil {
+		insideTemp = *s.InsideTemp
+	}
+
+	r.EffCoeff = r.Coeff + calc.InfiltrationCoeff(s.Width, s.Height, s.Depth, insideTemp, s.Infiltration)
+
+	var err error
+	r.TimeMinutes, r.InsideTemps, r.ACRunning, err = calc.CalculateTemperatureProfileContext(ctx, s.Width, s.Height, s.Depth, insideTemp, outsideTemps, r.EffCoeff, s.AC, progress)
+	if err != nil {
+		return Result{}, err
+	}
+	r.OutsideTemps = append([]float64(nil), outsideTemps[:len(r.InsideTemps)]...)
+
+	r.PeakInsideTemp, r.MinInsideTemp = math.Inf(-1), math.Inf(1)
+	for _, t := range r.InsideTemps {
+		r.PeakInsideTemp = math.Max(r.PeakInsideTemp, t)
+		r.MinInsideTemp = math.Min(r.MinInsideTemp, t)
+	}
+
+	for _, running := range r.ACRunning {
+		if running {
+			r.ACMinutes++
+		}
+	}
+	if s.AC != nil && s.AC.Enabled {
+		r.DailyKWh = math.Abs(s.AC.CoolingPower) / 1000.0 * float64(r.ACMinutes) / 60.0
+		// same 30 day month as CalculateACCostForSimulation
+		r.ACCost = calc.CalculateACCostBreakdown(s.AC, 30, s.ExistingUsage, r.ACRunning)
+		r.MonthlyACCost = r.ACCost.Total
+	}
+
+	return r, nil
+}
+
+// Cooling reports whether the AC cooled the room at minute i. The solver
+// leaves the compressor state on outside the operating window but applies no
+// cooling there.
+func (r Result) Cooling(i int) bool {
+	ac := r.Scenario.AC
+	return ac != nil && ac.Enabled && i < len(r.ACRunning) && r.ACRunning[i] && i >= ac.OnTime && i < ac.OffTime
+}
+
+// CoolingKWh is the AC energy of the minutes the solver cooled the room.
+// DailyKWh also bills compressor minutes outside the operating window.
+func (r Result) CoolingKWh() float64 {
+	if r.Scenario.AC == nil {
+		return 0
+	}
+	minutes := 0
+	for i := range r.ACRunning {
+		if r.Cooling(i) {
+			minutes++
+		}
+	}
+	return math.Abs(r.Scenario.AC.CoolingPower) / 1000.0 * float64(minutes) / 60.0
+}
+
+// LoadScenarios reads a JSON array of scenarios, or a single scenario object.
+func LoadScenarios(path string) ([]Scenario, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Scenario
+	if trimmed := bytes.TrimSpace(buf); len(trimmed) > 0 && trimmed[0] == '{' {
+		var s Scenario
+		if err := json.Unmarshal(buf, &s); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		list = append(list, s)
+	} else if err := json.Unmarshal(buf, &list); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("%s: %w", path, errors.New("no scenarios defined"))
+	}
+	return list, nil
+}
@@ -0,0 +1,23 @@
+// Package scenariotest provides a scenario and weather day shared by the
+// tests of the packages built on scenario.
+package scenariotest
+
+import (
+	"heat-transfer/calc"
+	"heat-transfer/scenario"
+	"math"
+)
+
+// Room returns a small room cooled from 09:00 to 17:00 and the outside
+// temperature of a hot day, rising from 30 °C to 36 °C at 12:00.
+func Room() (scenario.Scenario, [840]float64) {
+	s := scenario.Scenario{
+		Name: "room", Width: 4, Height: 3, Depth: 5, Coeff: 1.5,
+		AC: &calc.ACParams{Enabled: true, OnTime: 240, OffTime: 720, SetTemp: 26, CoolingPower: -2000},
+	}
+	var outside [840]float64
+	for m := range outside {
+		outside[m] = 30 + 6*math.Sin(math.Pi*float64(m)/840)
+	}
+	return s, outside
+}
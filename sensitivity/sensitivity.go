@@ -0,0 +1,312 @@
+package sensitivity
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"heat-transfer/scenario"
+	"heat-transfer/uncertainty"
+	"io"
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// TornadoBar is the swing of one output when one input moves from its low
+// to its high value while every other input stays at its nominal value.
+type TornadoBar struct {
+	Input     string  `json:"input"`
+	Output    string  `json:"output"`
+	LowInput  float64 `json:"low_input"`
+	HighInput float64 `json:"high_input"`
+	Low       float64 `json:"low"`  // output at LowInput
+	High      float64 `json:"high"` // output at HighInput
+	Swing     float64 `json:"swing"`
+}
+
+type Tornado struct {
+	Baseline map[string]float64      `json:"baseline"` // output at nominal inputs
+	Bars     map[string][]TornadoBar `json:"bars"`     // per output, largest swing first
+}
+
+// RunTornado evaluates each input at the given low and high percentiles of
+// its distribution, e.g. 10 and 90.
+func RunTornado(base scenario.Scenario, outsideTemps [840]float64, inputs []uncertainty.Input, lowPct, highPct float64) (Tornado, error) {
+	if err := uncertainty.ValidateInputs(base, inputs); err != nil {
+		return Tornado{}, err
+	}
+	for _, pct := range []float64{lowPct, highPct} {
+		if pct < 0 || pct > 100 {
+			return Tornado{}, fmt.Errorf("invalid percentile %g, expected 0 to 100", pct)
+		}
+	}
+
+	nominal := map[string]float64{}
+	for _, in := range inputs {
+		nominal[in.Name] = in.Distribution.Nominal()
+	}
+
+	baseOut, err := uncertainty.Evaluate(base, outsideTemps, nominal)
+	if err != nil {
+		return Tornado{}, err
+	}
+
+	t := Tornado{Baseline: map[string]float64{}, Bars: map[string][]TornadoBar{}}
+	for _, name := range uncertainty.OutputNames() {
+		t.Baseline[name] = baseOut.Get(name)
+	}
+
+	for _, in := range inputs {
+		lowIn := in.Distribution.Quantile(lowPct / 100)
+		highIn := in.Distribution.Quantile(highPct / 100)
+
+		outs := [2]uncertainty.Outputs{}
+		for i, v := range []float64{lowIn, highIn} {
+			values := map[string]float64{}
+			for k, nv := range nominal {
+				values[k] = nv
+			}
+			values[in.Name] = v
+
+			if outs[i], err = uncertainty.Evaluate(base, outsideTemps, values); err != nil {
+				return Tornado{}, fmt.Errorf("input %q: %w", in.Name, err)
+			}
+		}
+
+		for _, name := range uncertainty.OutputNames() {
+			low, high := outs[0].Get(name), outs[1].Get(name)
+			t.Bars[name] = append(t.Bars[name], TornadoBar{
+				Input:     in.Name,
+				Output:    name,
+				LowInput:  lowIn,
+				HighInput: highIn,
+				Low:       low,
+				High:      high,
+				Swing:     math.Abs(high - low),
+			})
+		}
+	}
+
+	for _, bars := range t.Bars {
+		sort.SliceStable(bars, func(i, j int) bool { return bars[i].Swing > bars[j].Swing })
+	}
+	return t, nil
+}
+
+// SobolIndex holds the first order and total effect index of one input on one output.
+type SobolIndex struct {
+	Input      string  `json:"input"`
+	Output     string  `json:"output"`
+	FirstOrder float64 `json:"first_order"`
+	Total      float64 `json:"total"`
+}
+
+type Sobol struct {
+	Samples     int                     `json:"samples"`
+	Seed        uint64                  `json:"seed"`
+	Evaluations int                     `json:"evaluations"`
+	Variance    map[string]float64      `json:"variance"`
+	Indices     map[string][]SobolIndex `json:"indices"` // per output, largest total effect first
+}
+
+// RunSobol estimates variance based indices with Saltelli sampling, using the
+// Saltelli (2010) first order and Jansen total effect estimators. It needs
+// samples * (inputs + 2) model runs.
+func RunSobol(ctx context.Context, base scenario.Scenario, outsideTemps [840]float64, inputs []uncertainty.Input, samples int, seed uint64, workers int) (Sobol, error) {
+	if err := uncertainty.ValidateInputs(base, inputs); err != nil {
+		return Sobol{}, err
+	}
+	if samples < 2 {
+		return Sobol{}, errors.New("at least two samples are required")
+	}
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	k := len(inputs)
+	rows := saltelliRows(k, samples, seed)
+	outs, err := evaluateRows(ctx, base, outsideTemps, inputs, rows, workers)
+	if err != nil {
+		return Sobol{}, err
+	}
+
+	res := Sobol{
+		Samples:     samples,
+		Seed:        seed,
+		Evaluations: len(rows),
+		Variance:    map[string]float64{},
+		Indices:     map[string][]SobolIndex{},
+	}
+
+	for _, name := range uncertainty.OutputNames() {
+		f := make([]float64, len(outs))
+		for r, o := range outs {
+			f[r] = o.Get(name)
+		}
+		variance, first, total := sobolIndices(f, samples, k)
+		res.Variance[name] = variance
+
+		for i, in := range inputs {
+			res.Indices[name] = append(res.Indices[name], SobolIndex{Input: in.Name, Output: name, FirstOrder: first[i], Total: total[i]})
+		}
+		sort.SliceStable(res.Indices[name], func(x, y int) bool {
+			return res.Indices[name][x].Total > res.Indices[name][y].Total
+		})
+	}
+
+	return res, nil
+}
+
+// saltelliRows draws the quantiles of k inputs for the matrices A and B and
+// returns the rows A, B, then AB_i for each input i: A with column i from B.
+func saltelliRows(k, samples int, seed uint64) [][]float64 {
+	rng := rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+	a := make([][]float64, samples)
+	b := make([][]float64, samples)
+	for j := range samples {
+		a[j] = make([]float64, k)
+		b[j] = make([]float64, k)
+		for i := range k {
+			a[j][i] = rng.Float64()
+			b[j][i] = rng.Float64()
+		}
+	}
+
+	rows := make([][]float64, 0, samples*(k+2))
+	rows = append(rows, a...)
+	rows = append(rows, b...)
+	for i := range k {
+		for j := range samples {
+			ab := append([]float64(nil), a[j]...)
+			ab[i] = b[j][i]
+			rows = append(rows, ab)
+		}
+	}
+	return rows
+}
+
+// sobolIndices estimates the output variance and the first order and total
+// effect index of each input from f, the model evaluated at saltelliRows.
+func sobolIndices(f []float64, samples, k int) (variance float64, first, total []float64) {
+	fa, fb := f[:samples], f[samples:2*samples]
+	mean, variance := meanVariance(f[:2*samples])
+
+	first, total = make([]float64, k), make([]float64, k)
+	if variance <= 0 {
+		return variance, first, total
+	}
+	for i := range k {
+		fab := f[(2+i)*samples : (3+i)*samples]
+		s1, st := 0.0, 0.0
+		for j := range samples {
+			// centring f_B keeps the estimator stable when the mean is large
+			s1 += (fb[j] - mean) * (fab[j] - fa[j])
+			st += (fa[j] - fab[j]) * (fa[j] - fab[j])
+		}
+		first[i] = s1 / float64(samples) / variance
+		total[i] = st / (2 * float64(samples)) / variance
+	}
+	return variance, first, total
+}
+
+func evaluateRows(ctx context.Context, base scenario.Scenario, outsideTemps [840]float64, inputs []uncertainty.Input, rows [][]float64, workers int) ([]uncertainty.Outputs, error) {
+	outs := make([]uncertainty.Outputs, len(rows))
+	errs := make([]error, len(rows))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(workers, len(rows)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range jobs {
+				values := map[string]float64{}
+				for i, in := range inputs {
+					values[in.Name] = in.Distribution.Quantile(rows[r][i])
+				}
+				outs[r], errs[r] = uncertainty.EvaluateContext(ctx, base, outsideTemps, values)
+			}
+		}()
+	}
+
+feed:
+	for r := range rows {
+		select {
+		case jobs <- r:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	// the estimators need every row, so a single failure invalidates the run
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return outs, nil
+}
+
+func meanVariance(values []float64) (float64, float64) {
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - mean) * (v - mean)
+	}
+	return mean, sum / float64(len(values)-1)
+}
+
+// WriteCSV writes every tornado bar, grouped by output.
+func (t Tornado) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 4, 64) }
+
+	if err := cw.Write([]string{"output", "input", "baseline", "low_input", "high_input", "low", "high", "swing"}); err != nil {
+		return err
+	}
+	for _, name := range uncertainty.OutputNames() {
+		for _, bar := range t.Bars[name] {
+			rec := []string{name, bar.Input, f(t.Baseline[name]), f(bar.LowInput), f(bar.HighInput), f(bar.Low), f(bar.High), f(bar.Swing)}
+			if err := cw.Write(rec); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteCSV writes every index, grouped by output.
+func (s Sobol) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 4, 64) }
+
+	if err := cw.Write([]string{"output", "input", "first_order", "total"}); err != nil {
+		return err
+	}
+	for _, name := range uncertainty.OutputNames() {
+		for _, idx := range s.Indices[name] {
+			if err := cw.Write([]string{name, idx.Input, f(idx.FirstOrder), f(idx.Total)}); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
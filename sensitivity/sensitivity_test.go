@@ -0,0 +1,152 @@
+package sensitivity
+
+import (
+	"context"
+	"heat-transfer/scenario/scenariotest"
+	"heat-transfer/uncertainty"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSobolIndicesLinear(t *testing.T) {
+	// Y = sum c_i X_i with independent uniform X_i: V_i = c_i²/12, so
+	// S_i = ST_i = c_i² / sum c²
+	tests := []struct {
+		name   string
+		coeffs []float64
+	}{
+		{"one input", []float64{3}},
+		{"unequal", []float64{1, 2, 0}},
+		{"negative", []float64{-1, 1, 2}},
+	}
+	const samples = 20000
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := len(tt.coeffs)
+			rows := saltelliRows(k, samples, 1)
+			if len(rows) != samples*(k+2) {
+				t.Fatalf("%d rows, want %d", len(rows), samples*(k+2))
+			}
+			f := make([]float64, len(rows))
+			for r, x := range rows {
+				for i, c := range tt.coeffs {
+					f[r] += c * x[i]
+				}
+			}
+
+			sumSq := 0.0
+			for _, c := range tt.coeffs {
+				sumSq += c * c
+			}
+			variance, first, total := sobolIndices(f, samples, k)
+			if want := sumSq / 12; math.Abs(variance-want) > 0.03*want {
+				t.Errorf("variance %g, want %g", variance, want)
+			}
+			for i, c := range tt.coeffs {
+				want := c * c / sumSq
+				if math.Abs(first[i]-want) > 0.03 || math.Abs(total[i]-want) > 0.03 {
+					t.Errorf("input %d: first order %.3f, total %.3f, want %.3f", i, first[i], total[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestSobolIndicesInteraction(t *testing.T) {
+	// Y = X1 X2: each input alone explains 3/7 of the variance and their
+	// interaction the remaining 1/7, which counts towards both totals
+	const samples = 40000
+	rows := saltelliRows(2, samples, 3)
+	f := make([]float64, len(rows))
+	for r, x := range rows {
+		f[r] = x[0] * x[1]
+	}
+	_, first, total := sobolIndices(f, samples, 2)
+	for i := range 2 {
+		if math.Abs(first[i]-3.0/7) > 0.03 || math.Abs(total[i]-4.0/7) > 0.03 {
+			t.Errorf("input %d: first order %.3f, total %.3f, want %.3f and %.3f", i, first[i], total[i], 3.0/7, 4.0/7)
+		}
+	}
+}
+
+func TestSobolIndicesConstant(t *testing.T) {
+	f := make([]float64, 10*4)
+	for i := range f {
+		f[i] = 5
+	}
+	variance, first, total := sobolIndices(f, 10, 2)
+	if variance != 0 || first[0] != 0 || total[1] != 0 {
+		t.Errorf("a constant output should have no variance or indices, got %g, %v, %v", variance, first, total)
+	}
+}
+
+func TestRunSobolTariff(t *testing.T) {
+	// the monthly cost is linear in the tariff and nothing else varies it
+	s, outside := scenariotest.Room()
+	inputs := []uncertainty.Input{
+		{Name: uncertainty.ParamTariff, Distribution: uncertainty.Distribution{Kind: uncertainty.Uniform, Min: 1, Max: 1.3}},
+		{Name: uncertainty.ParamSetTemp, Distribution: uncertainty.Distribution{Kind: uncertainty.Fixed, Mean: 26}},
+	}
+	res, err := RunSobol(context.Background(), s, outside, inputs, 1024, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Evaluations != 1024*4 {
+		t.Errorf("%d evaluations, want %d", res.Evaluations, 1024*4)
+	}
+	cost := res.Indices[uncertainty.OutputMonthlyCost]
+	if cost[0].Input != uncertainty.ParamTariff || math.Abs(cost[0].FirstOrder-1) > 0.1 || math.Abs(cost[0].Total-1) > 0.1 {
+		t.Errorf("cost indices %+v, want the tariff to explain everything", cost)
+	}
+	if cost[1].Total != 0 {
+		t.Errorf("a fixed input has total effect %g", cost[1].Total)
+	}
+	if v := res.Variance[uncertainty.OutputPeakInsideTemp]; v > 1e-12 {
+		t.Errorf("peak temperature variance %g, the tariff does not change it", v)
+	}
+}
+
+func TestRunTornado(t *testing.T) {
+	s, outside := scenariotest.Room()
+	inputs := []uncertainty.Input{
+		{Name: uncertainty.ParamTariff, Distribution: uncertainty.Distribution{Kind: uncertainty.Uniform, Min: 1, Max: 1.3}},
+		{Name: uncertainty.ParamConductivity, Distribution: uncertainty.Distribution{Kind: uncertainty.Normal, Mean: 1, StdDev: 0.2}},
+	}
+	tor, err := RunTornado(s, outside, inputs, 10, 90)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// only the conductivity moves the temperature
+	peak := tor.Bars[uncertainty.OutputPeakInsideTemp]
+	if peak[0].Input != uncertainty.ParamConductivity || peak[0].Swing <= 0 || peak[1].Swing != 0 {
+		t.Errorf("peak temperature bars %+v, want conductivity first and no tariff swing", peak)
+	}
+	for _, bar := range tor.Bars[uncertainty.OutputMonthlyCost] {
+		if bar.Input == uncertainty.ParamTariff {
+			base := tor.Baseline[uncertainty.OutputMonthlyCost] / 1.15
+			if math.Abs(bar.Low-1.03*base) > 1e-6 || math.Abs(bar.High-1.27*base) > 1e-6 {
+				t.Errorf("tariff bar %+v, want 1.03 and 1.27 times %g", bar, base)
+			}
+		}
+	}
+}
+
+func TestValidatesInputs(t *testing.T) {
+	s, outside := scenariotest.Room()
+	misspelled := []uncertainty.Input{{Name: "conductivty", Distribution: uncertainty.Distribution{Kind: uncertainty.Uniform, Min: 0.8, Max: 1.2}}}
+
+	if _, err := RunTornado(s, outside, misspelled, 10, 90); err == nil || !strings.Contains(err.Error(), "conductivty") {
+		t.Errorf("tornado error %v, want the unknown input named", err)
+	}
+	if _, err := RunSobol(context.Background(), s, outside, misspelled, 8, 1, 1); err == nil || !strings.Contains(err.Error(), "conductivty") {
+		t.Errorf("Sobol error %v, want the unknown input named", err)
+	}
+
+	for _, pct := range [][2]float64{{-5, 90}, {10, 150}} {
+		if _, err := RunTornado(s, outside, uncertainty.DefaultInputs(), pct[0], pct[1]); err == nil {
+			t.Errorf("percentiles %v should be refused", pct)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"heat-transfer/calc"
+	"heat-transfer/chartings"
+	"heat-transfer/constants"
+	"heat-transfer/scenario"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+
+	"gonum.org/v1/plot/vg"
+)
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPI)
+}
+
+func (s *Server) handleMaterials(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, constants.ListMaterials())
+}
+
+func (s *Server) handleMaterial(w http.ResponseWriter, r *http.Request) {
+	m, err := constants.GetMaterial(r.PathValue("key"))
+	if errors.Is(err, constants.ErrMaterialNotFound) {
+		writeError(w, notFound("material %q not found", r.PathValue("key")))
+		return
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, m)
+}
+
+// TariffBlock is one step of the progressive rate; UpTo is nil for the last,
+// unbounded block.
+type TariffBlock struct {
+	UpTo *float64 `json:"up_to_kwh"`
+	Rate float64  `json:"rate"` // THB/kWh
+}
+
+type Tariff struct {
+	Name       string        `json:"name"`
+	Currency   string        `json:"currency"`
+	Blocks     []TariffBlock `json:"blocks"`
+	ServiceFee float64       `json:"service_fee"` // per month
+	FtRate     float64       `json:"ft_rate"`     // per kWh
+	VatPercent float64       `json:"vat_percent"`
+}
+
+func (s *Server) handleTariffs(w http.ResponseWriter, r *http.Request) {
+	rate := calc.GetResidentialRate()
+	t := Tariff{
+		Name:       "residential",
+		Currency:   constants.BaseCurrency,
+		ServiceFee: rate.ServiceFee,
+		FtRate:     rate.FtRate,
+		VatPercent: rate.VatPercent,
+	}
+	for i, limit := range rate.Blocks {
+		b := TariffBlock{Rate: rate.BlockRates[i]}
+		if !math.IsInf(limit, 1) {
+			b.UpTo = &limit
+		}
+		t.Blocks = append(t.Blocks, b)
+	}
+	writeJSON(w, http.StatusOK, []Tariff{t})
+}
+
+// SimulateRequest is the body of the simulate and chart endpoints.
+type SimulateRequest struct {
+	Scenario scenario.Scenario `json:"scenario"`
+	Weather  WeatherInput      `json:"weather"`
+}
+
+// simulate decodes and runs the scenario of a request.
+func (s *Server) simulate(w http.ResponseWriter, r *http.Request) (scenario.Result, error) {
+	var req SimulateRequest
+	if err := decode(w, r, &req); err != nil {
+		return scenario.Result{}, err
+	}
+	if req.Scenario.Name == "" {
+		req.Scenario.Name = "Scenario"
+	}
+	if err := req.Scenario.Validate(); err != nil {
+		return scenario.Result{}, badRequest("%v", err)
+	}
+
+	outside, err := s.outsideTemps(req.Weather)
+	if err != nil {
+		return scenario.Result{}, err
+	}
+
+	// Run does no I/O, so every failure is down to the scenario
+	res, err := scenario.Run(req.Scenario, outside)
+	if err != nil {
+		return scenario.Result{}, badRequest("%v", err)
+	}
+	return res, nil
+}
+
+func (s *Server) handleSimulate(w http.ResponseWriter, r *http.Request) {
+	res, err := s.simulate(w, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if r.URL.Query().Get("series") == "false" {
+		res.TimeMinutes, res.InsideTemps, res.OutsideTemps, res.ACRunning = nil, nil, nil, nil
+		res.Weather = nil
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+var contentTypes = map[string]string{
+	chartings.FormatPNG: "image/png",
+	chartings.FormatSVG: "image/svg+xml",
+	chartings.FormatPDF: "application/pdf",
+}
+
+func (s *Server) handleChart(w http.ResponseWriter, r *http.Request) {
+	o := chartings.DefaultOptions()
+	q := r.URL.Query()
+	if f := q.Get("format"); f != "" {
+		o.Format = f
+	}
+	contentType, ok := contentTypes[o.Format]
+	if !ok {
+		writeError(w, badRequest("unsupported format %q, use png, svg or pdf", o.Format))
+		return
+	}
+	for name, dst := range map[string]*vg.Length{"width": &o.Width, "height": &o.Height} {
+		v := q.Get(name)
+		if v == "" {
+			continue
+		}
+		inches, err := strconv.ParseFloat(v, 64)
+		if err != nil || inches <= 0 || inches > 40 {
+			writeError(w, badRequest("%s must be a number of inches between 0 and 40", name))
+			return
+		}
+		*dst = vg.Length(inches) * vg.Inch
+	}
+	o.Title = chartings.Titles[r.PathValue("type")]
+	if t := q.Get("title"); t != "" {
+		o.Title = t
+	}
+
+	res, err := s.simulate(w, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var write func(io.Writer) error
+	switch r.PathValue("type") {
+	case chartings.ChartTemperature:
+		if ac := res.Scenario.AC; ac != nil && ac.Enabled {
+			o.SetPoint = &ac.SetTemp
+			o.AC = &chartings.ACOverlay{Running: res.ACRunning, OnTime: ac.OnTime, OffTime: ac.OffTime, CoolingPower: ac.CoolingPower}
+			if q.Get("height") == "" {
+				o.Height = 5 * vg.Inch
+			}
+		}
+		write = func(w io.Writer) error {
+			return chartings.WriteTemperature(w,
+				chartings.XYs(res.TimeMinutes, res.InsideTemps),
+				chartings.XYs(res.TimeMinutes, res.OutsideTemps), o)
+		}
+	case chartings.ChartHeatFlow:
+		x, stacks := chartings.Resample(res.TimeMinutes, chartings.HeatFlowStacks(res), 15)
+		write = func(w io.Writer) error { return chartings.WriteHeatFlow(w, x, stacks, o) }
+	case chartings.ChartCost:
+		write = func(w io.Writer) error {
+			return chartings.WriteCost(w, chartings.ACCostBars(res.ACCost), "THB/month", o)
+		}
+	case chartings.ChartMaterial:
+		write = func(w io.Writer) error {
+			return chartings.WriteCost(w, chartings.BOQCostBars(res.BOQ), "THB", o)
+		}
+	default:
+		writeError(w, notFound("unknown chart type %q", r.PathValue("type")))
+		return
+	}
+
+	// render first so a failure can still be reported as JSON
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(buf.Bytes())
+}
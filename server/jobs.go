@@ -0,0 +1,194 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"heat-transfer/batch"
+	"heat-transfer/jobs"
+	"heat-transfer/scenario"
+	"heat-transfer/uncertainty"
+	"net/http"
+)
+
+// Job kinds.
+const (
+	JobSimulate   = "simulate"
+	JobMonteCarlo = "montecarlo"
+	JobBatch      = "batch"
+)
+
+// limits that keep a single job from occupying a worker for hours, or from
+// holding more than about 70 MB of per-minute inside temperatures while the
+// Monte Carlo bands are computed
+const (
+	maxSamples    = 10000
+	maxGridPoints = 10000
+)
+
+// each job runs on one goroutine, so the queue's workers bound the CPUs in
+// use
+const jobWorkers = 1
+
+// JobRequest is the body of a job submission. MonteCarlo is required for
+// montecarlo jobs and Grid for batch jobs.
+type JobRequest struct {
+	Type       string            `json:"type"`
+	Scenario   scenario.Scenario `json:"scenario"`
+	Weather    WeatherInput      `json:"weather"`
+	MonteCarlo *MonteCarloInput  `json:"montecarlo,omitempty"`
+	Grid       *batch.Grid       `json:"grid,omitempty"`
+}
+
+type MonteCarloInput struct {
+	Samples     int                 `json:"samples"`
+	Seed        uint64              `json:"seed"`
+	Percentiles []float64           `json:"percentiles,omitempty"`
+	Inputs      []uncertainty.Input `json:"inputs,omitempty"` // defaults to uncertainty.DefaultInputs
+}
+
+// jobFunc validates a submission and returns the work to queue.
+func (s *Server) jobFunc(req JobRequest) (jobs.Func, error) {
+	if req.Scenario.Name == "" {
+		req.Scenario.Name = "Scenario"
+	}
+	if err := req.Scenario.Validate(); err != nil {
+		return nil, badRequest("%v", err)
+	}
+
+	switch req.Type {
+	case JobSimulate:
+	case JobMonteCarlo:
+		if req.MonteCarlo == nil {
+			return nil, badRequest("montecarlo jobs need a montecarlo section")
+		}
+		mc := req.MonteCarlo
+		if mc.Samples <= 0 || mc.Samples > maxSamples {
+			return nil, badRequest("montecarlo.samples must be between 1 and %d", maxSamples)
+		}
+		if len(mc.Inputs) == 0 {
+			mc.Inputs = uncertainty.DefaultInputs()
+		}
+		if err := uncertainty.ValidateInputs(req.Scenario, mc.Inputs); err != nil {
+			return nil, badRequest("%v", err)
+		}
+		for _, p := range mc.Percentiles {
+			if p < 0 || p > 100 {
+				return nil, badRequest("percentiles must lie between 0 and 100")
+			}
+		}
+	case JobBatch:
+		if req.Grid == nil {
+			return nil, badRequest("batch jobs need a grid section")
+		}
+		if n := req.Grid.Size(); n > maxGridPoints {
+			return nil, badRequest("grid has %d points, at most %d are allowed", n, maxGridPoints)
+		}
+		if _, _, err := req.Grid.Expand(req.Scenario); err != nil {
+			return nil, badRequest("%v", err)
+		}
+	default:
+		return nil, badRequest("unknown job type %q, use simulate, montecarlo or batch", req.Type)
+	}
+
+	// resolved now so a bad location is reported on submission
+	outside, err := s.outsideTemps(req.Weather)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.Type {
+	case JobMonteCarlo:
+		mc := req.MonteCarlo
+		return func(ctx context.Context, progress func(done, total int)) (any, error) {
+			res, err := uncertainty.RunMonteCarlo(ctx, req.Scenario, outside, mc.Inputs, uncertainty.MonteCarloParams{
+				Samples:     mc.Samples,
+				Seed:        mc.Seed,
+				Percentiles: mc.Percentiles,
+				Workers:     jobWorkers,
+				Progress:    progress,
+			})
+			// the per-sample series are not part of the response, do not
+			// keep them until the job expires
+			res.InputValues, res.Outputs = nil, nil
+			return res, err
+		}, nil
+	case JobBatch:
+		return func(ctx context.Context, progress func(done, total int)) (any, error) {
+			return batch.Run(ctx, req.Scenario, *req.Grid, outside, jobWorkers, progress)
+		}, nil
+	default:
+		return func(ctx context.Context, progress func(done, total int)) (any, error) {
+			return scenario.RunContext(ctx, req.Scenario, outside, progress)
+		}, nil
+	}
+}
+
+func (s *Server) handleSubmitJob(w http.ResponseWriter, r *http.Request) {
+	var req JobRequest
+	if err := decode(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	fn, err := s.jobFunc(req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	st, err := s.jobs.Submit(req.Type, fn)
+	if errors.Is(err, jobs.ErrQueueFull) {
+		w.Header().Set("Retry-After", "30")
+		writeError(w, &requestError{status: http.StatusServiceUnavailable, msg: err.Error()})
+		return
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/jobs/"+st.ID)
+	writeJSON(w, http.StatusAccepted, st)
+}
+
+// jobError maps queue errors onto responses.
+func jobError(w http.ResponseWriter, id string, err error) {
+	if errors.Is(err, jobs.ErrNotFound) {
+		err = notFound("job %q not found or expired", id)
+	}
+	writeError(w, err)
+}
+
+func (s *Server) handleJobStatus(w http.ResponseWriter, r *http.Request) {
+	st, err := s.jobs.Status(r.PathValue("id"))
+	if err != nil {
+		jobError(w, r.PathValue("id"), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, st)
+}
+
+func (s *Server) handleJobResult(w http.ResponseWriter, r *http.Request) {
+	result, st, err := s.jobs.Result(r.PathValue("id"))
+	if err != nil {
+		jobError(w, r.PathValue("id"), err)
+		return
+	}
+
+	switch st.State {
+	case jobs.Succeeded:
+		writeJSON(w, http.StatusOK, result)
+	case jobs.Failed:
+		writeError(w, &requestError{status: http.StatusConflict, msg: "job failed: " + st.Error})
+	default:
+		writeError(w, &requestError{status: http.StatusConflict, msg: "job is " + string(st.State)})
+	}
+}
+
+func (s *Server) handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	st, err := s.jobs.Cancel(r.PathValue("id"))
+	if err != nil {
+		jobError(w, r.PathValue("id"), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, st)
+}
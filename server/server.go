@@ -0,0 +1,168 @@
+// Package server exposes simulations, materials, tariffs and charts over a
+// JSON HTTP API.
+package server
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"heat-transfer/interop"
+	"heat-transfer/jobs"
+	"heat-transfer/quality"
+	weatherdata "heat-transfer/weatherData"
+	"net/http"
+)
+
+//go:embed openapi.json
+var openAPI []byte
+
+// largest request body accepted
+const maxBodyBytes = 1 << 20
+
+// Config selects where outside temperatures come from.
+type Config struct {
+	// DefaultWeather supplies the outside temperatures for requests that do
+	// not bring their own. Nil makes weather mandatory in every request.
+	DefaultWeather func() ([840]float64, error)
+
+	// Provider serves requests naming a location. Nil keeps the server
+	// offline.
+	Provider weatherdata.Provider
+
+	// Jobs bounds the background jobs of the /jobs endpoints.
+	Jobs jobs.Config
+}
+
+type Server struct {
+	cfg  Config
+	mux  *http.ServeMux
+	jobs *jobs.Queue
+}
+
+func New(cfg Config) *Server {
+	s := &Server{cfg: cfg, mux: http.NewServeMux(), jobs: jobs.NewQueue(cfg.Jobs)}
+
+	s.mux.HandleFunc("GET /api/v1/health", s.handleHealth)
+	s.mux.HandleFunc("GET /api/v1/openapi.json", s.handleOpenAPI)
+	s.mux.HandleFunc("GET /api/v1/materials", s.handleMaterials)
+	s.mux.HandleFunc("GET /api/v1/materials/{key}", s.handleMaterial)
+	s.mux.HandleFunc("GET /api/v1/tariffs", s.handleTariffs)
+	s.mux.HandleFunc("POST /api/v1/simulate", s.handleSimulate)
+	s.mux.HandleFunc("POST /api/v1/charts/{type}", s.handleChart)
+	s.mux.HandleFunc("POST /api/v1/jobs", s.handleSubmitJob)
+	s.mux.HandleFunc("GET /api/v1/jobs/{id}", s.handleJobStatus)
+	s.mux.HandleFunc("GET /api/v1/jobs/{id}/result", s.handleJobResult)
+	s.mux.HandleFunc("DELETE /api/v1/jobs/{id}", s.handleCancelJob)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Close cancels the background jobs and waits for them to stop.
+func (s *Server) Close() {
+	s.jobs.Close()
+}
+
+// WeatherInput is the optional weather section of a request. Hourly takes
+// precedence over Location.
+type WeatherInput struct {
+	Hourly []float64 `json:"hourly,omitempty"` // °C from 05:00 to 19:00
+	// minute after 05:00 of each hourly value, consecutive hours when empty
+	Minutes       []float64        `json:"minutes,omitempty"`
+	Interpolation *interop.Options `json:"interpolation,omitempty"`
+	Location      string           `json:"location,omitempty"`
+}
+
+// outsideTemps resolves the outside temperatures of a request.
+func (s *Server) outsideTemps(in WeatherInput) ([840]float64, error) {
+	switch {
+	case len(in.Hourly) > 0:
+		opts := interop.Default
+		if in.Interpolation != nil {
+			opts = *in.Interpolation
+		}
+		if len(in.Minutes) == 0 {
+			if len(in.Hourly) < 2 || len(in.Hourly) > 15 {
+				return [840]float64{}, badRequest("weather.hourly must hold between 2 and 15 values from 05:00")
+			}
+			in.Minutes = interop.HourlyMinutes(len(in.Hourly))
+		}
+		if len(in.Hourly) < 2 || len(in.Hourly) > 841 {
+			return [840]float64{}, badRequest("weather.hourly must hold between 2 and 841 values with weather.minutes")
+		}
+		minutes, temps, _, err := quality.Check(in.Minutes, in.Hourly, quality.Default)
+		if err != nil {
+			return [840]float64{}, badRequest("weather: %v", err)
+		}
+		outside, err := opts.Day(minutes, temps)
+		if err != nil {
+			return [840]float64{}, badRequest("weather: %v", err)
+		}
+		return outside, nil
+	case in.Location != "":
+		if s.cfg.Provider == nil {
+			return [840]float64{}, badRequest("the server is offline, send weather.hourly instead of a location")
+		}
+		w, err := s.cfg.Provider.Forecast(in.Location)
+		return w.Temp, err
+	case s.cfg.DefaultWeather != nil:
+		return s.cfg.DefaultWeather()
+	default:
+		return [840]float64{}, badRequest("weather.hourly or weather.location is required")
+	}
+}
+
+// requestError is an error caused by the client.
+type requestError struct {
+	status int
+	msg    string
+}
+
+func (e *requestError) Error() string { return e.msg }
+
+func badRequest(format string, args ...any) error {
+	return &requestError{status: http.StatusBadRequest, msg: fmt.Sprintf(format, args...)}
+}
+
+func notFound(format string, args ...any) error {
+	return &requestError{status: http.StatusNotFound, msg: fmt.Sprintf(format, args...)}
+}
+
+// decode reads a JSON body into v, rejecting unknown fields and trailing data.
+func decode(w http.ResponseWriter, r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return badRequest("invalid request body: %v", err)
+	}
+	if dec.More() {
+		return badRequest("invalid request body: unexpected data after the JSON value")
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeError answers with the status of a requestError, 500 otherwise.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var re *requestError
+	if errors.As(err, &re) {
+		status = re.status
+	}
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
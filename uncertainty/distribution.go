@@ -0,0 +1,83 @@
+package uncertainty
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+)
+
+const (
+	Fixed      = "fixed"
+	Normal     = "normal"
+	Uniform    = "uniform"
+	Triangular = "triangular"
+)
+
+// Distribution describes an uncertain input. Normal uses Mean and StdDev,
+// uniform uses Min and Max, triangular uses Min, Mode and Max and fixed uses Mean.
+type Distribution struct {
+	Kind   string  `json:"kind"`
+	Mean   float64 `json:"mean,omitempty"`
+	StdDev float64 `json:"std_dev,omitempty"`
+	Min    float64 `json:"min,omitempty"`
+	Mode   float64 `json:"mode,omitempty"`
+	Max    float64 `json:"max,omitempty"`
+}
+
+func (d Distribution) Validate() error {
+	switch d.Kind {
+	case Fixed:
+	case Normal:
+		if d.StdDev < 0 {
+			return fmt.Errorf("normal distribution: std_dev must not be negative")
+		}
+	case Uniform:
+		if d.Max < d.Min {
+			return fmt.Errorf("uniform distribution: max must be >= min")
+		}
+	case Triangular:
+		if d.Min > d.Mode || d.Mode > d.Max || d.Min == d.Max {
+			return fmt.Errorf("triangular distribution: requires min <= mode <= max and min < max")
+		}
+	default:
+		return fmt.Errorf("unknown distribution %q", d.Kind)
+	}
+	return nil
+}
+
+// Sample draws one value from the distribution.
+func (d Distribution) Sample(r *rand.Rand) float64 {
+	return d.Quantile(r.Float64())
+}
+
+// Quantile maps u in [0, 1) onto the distribution.
+func (d Distribution) Quantile(u float64) float64 {
+	switch d.Kind {
+	case Normal:
+		// clamp away from 0 and 1 so the tails stay finite
+		u = math.Min(math.Max(u, 1e-12), 1-1e-12)
+		return d.Mean + d.StdDev*math.Sqrt2*math.Erfinv(2*u-1)
+	case Uniform:
+		return d.Min + u*(d.Max-d.Min)
+	case Triangular:
+		fc := (d.Mode - d.Min) / (d.Max - d.Min)
+		if u < fc {
+			return d.Min + math.Sqrt(u*(d.Max-d.Min)*(d.Mode-d.Min))
+		}
+		return d.Max - math.Sqrt((1-u)*(d.Max-d.Min)*(d.Max-d.Mode))
+	default:
+		return d.Mean
+	}
+}
+
+// Nominal is the central value used for deterministic runs.
+func (d Distribution) Nominal() float64 {
+	switch d.Kind {
+	case Uniform:
+		return (d.Min + d.Max) / 2
+	case Triangular:
+		return d.Mode
+	default:
+		return d.Mean
+	}
+}
@@ -0,0 +1,81 @@
+package uncertainty
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestQuantile(t *testing.T) {
+	tests := []struct {
+		name string
+		d    Distribution
+		u    float64
+		want float64
+	}{
+		{"fixed", Distribution{Kind: Fixed, Mean: 3}, 0.9, 3},
+		{"uniform low", Distribution{Kind: Uniform, Min: 1, Max: 1.3}, 0, 1},
+		{"uniform middle", Distribution{Kind: Uniform, Min: 1, Max: 1.3}, 0.5, 1.15},
+		{"normal median", Distribution{Kind: Normal, Mean: 1, StdDev: 0.1}, 0.5, 1},
+		{"normal one sigma", Distribution{Kind: Normal, Mean: 1, StdDev: 0.1}, 0.8413447461, 1.1},
+		{"triangular mode", Distribution{Kind: Triangular, Min: 0, Mode: 1, Max: 4}, 0.25, 1},
+		{"triangular median", Distribution{Kind: Triangular, Min: 0, Mode: 2, Max: 4}, 0.5, 2},
+		{"triangular low", Distribution{Kind: Triangular, Min: 0.3, Mode: 0.5, Max: 1.5}, 0, 0.3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.d.Quantile(tt.u); math.Abs(got-tt.want) > 1e-6 {
+				t.Errorf("quantile %g is %g, want %g", tt.u, got, tt.want)
+			}
+		})
+	}
+
+	// the tails stay finite
+	d := Distribution{Kind: Normal, Mean: 0, StdDev: 1}
+	if v := d.Quantile(0); math.IsInf(v, 0) || v > -6 {
+		t.Errorf("quantile 0 is %g, want a finite far tail", v)
+	}
+}
+
+func TestSampleMoments(t *testing.T) {
+	tests := []struct {
+		d        Distribution
+		mean, sd float64
+	}{
+		{Distribution{Kind: Normal, Mean: 1, StdDev: 0.1}, 1, 0.1},
+		{Distribution{Kind: Uniform, Min: 1, Max: 1.3}, 1.15, 0.3 / math.Sqrt(12)},
+		// (a+b+c)/3 and sqrt((a²+b²+c²-ab-ac-bc)/18)
+		{Distribution{Kind: Triangular, Min: 0.3, Mode: 0.5, Max: 1.5}, 0.7666667, 0.2624669},
+	}
+	for _, tt := range tests {
+		t.Run(tt.d.Kind, func(t *testing.T) {
+			rng := rand.New(rand.NewPCG(1, 2))
+			const n = 100000
+			sum, sumSq := 0.0, 0.0
+			for range n {
+				v := tt.d.Sample(rng)
+				sum += v
+				sumSq += v * v
+			}
+			mean := sum / n
+			sd := math.Sqrt(sumSq/n - mean*mean)
+			if math.Abs(mean-tt.mean) > 0.01*tt.mean || math.Abs(sd-tt.sd) > 0.02*tt.sd {
+				t.Errorf("mean %g and sd %g, want %g and %g", mean, sd, tt.mean, tt.sd)
+			}
+		})
+	}
+}
+
+func TestDistributionValidate(t *testing.T) {
+	for _, d := range []Distribution{
+		{Kind: "lognormal"},
+		{Kind: Normal, StdDev: -1},
+		{Kind: Uniform, Min: 2, Max: 1},
+		{Kind: Triangular, Min: 1, Mode: 1, Max: 1},
+		{Kind: Triangular, Min: 0, Mode: 2, Max: 1},
+	} {
+		if err := d.Validate(); err == nil {
+			t.Errorf("%+v should be refused", d)
+		}
+	}
+}
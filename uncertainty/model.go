@@ -0,0 +1,185 @@
+package uncertainty
+
+import (
+	"context"
+	"fmt"
+	"heat-transfer/calc"
+	"heat-transfer/scenario"
+	"math"
+	"sort"
+)
+
+// Parameters that an Input can vary.
+const (
+	ParamConductivity  = "conductivity"   // multiplier on the envelope U value
+	ParamInfiltration  = "infiltration"   // air changes per hour
+	ParamTariff        = "tariff"         // multiplier on the electricity cost
+	ParamOutsideOffset = "outside_offset" // °C added to every outside temperature
+	ParamSetTemp       = "set_temp"       // AC set temperature in °C
+	ParamACPower       = "ac_power"       // AC cooling power in W
+)
+
+var parameterNames = []string{ParamConductivity, ParamInfiltration, ParamTariff, ParamOutsideOffset, ParamSetTemp, ParamACPower}
+
+// ParameterNames lists the parameters inputs can refer to.
+func ParameterNames() []string {
+	return append([]string(nil), parameterNames...)
+}
+
+type Input struct {
+	Name         string       `json:"name"`
+	Distribution Distribution `json:"distribution"`
+}
+
+func DefaultInputs() []Input {
+	return []Input{
+		{Name: ParamConductivity, Distribution: Distribution{Kind: Normal, Mean: 1, StdDev: 0.1}},
+		{Name: ParamInfiltration, Distribution: Distribution{Kind: Triangular, Min: 0.3, Mode: 0.5, Max: 1.5}},
+		{Name: ParamTariff, Distribution: Distribution{Kind: Uniform, Min: 1.0, Max: 1.3}},
+	}
+}
+
+// ValidateInputs checks the inputs can be applied to base. The AC inputs
+// need a base scenario with the AC enabled, they would be ignored otherwise.
+func ValidateInputs(base scenario.Scenario, inputs []Input) error {
+	if len(inputs) == 0 {
+		return fmt.Errorf("at least one uncertain input is required")
+	}
+
+	seen := map[string]bool{}
+	for _, in := range inputs {
+		known := false
+		for _, name := range parameterNames {
+			known = known || name == in.Name
+		}
+		if !known {
+			return fmt.Errorf("unknown input %q, expected one of %v", in.Name, parameterNames)
+		}
+		if seen[in.Name] {
+			return fmt.Errorf("input %q given twice", in.Name)
+		}
+		seen[in.Name] = true
+		if (in.Name == ParamSetTemp || in.Name == ParamACPower) && (base.AC == nil || !base.AC.Enabled) {
+			return fmt.Errorf("input %q needs a base scenario with the AC enabled", in.Name)
+		}
+
+		if err := in.Distribution.Validate(); err != nil {
+			return fmt.Errorf("input %q: %w", in.Name, err)
+		}
+	}
+	return nil
+}
+
+// Outputs are the model results uncertainty and sensitivity analyses look at.
+type Outputs struct {
+	InsideTemps    []float64 `json:"-"`
+	PeakInsideTemp float64   `json:"peak_inside_temp"`
+	ACMinutes      float64   `json:"ac_minutes"`
+	MonthlyCost    float64   `json:"monthly_cost"`
+}
+
+const (
+	OutputPeakInsideTemp = "peak_inside_temp"
+	OutputACMinutes      = "ac_minutes"
+	OutputMonthlyCost    = "monthly_cost"
+)
+
+var outputNames = []string{OutputPeakInsideTemp, OutputACMinutes, OutputMonthlyCost}
+
+func OutputNames() []string {
+	return append([]string(nil), outputNames...)
+}
+
+func (o Outputs) Get(name string) float64 {
+	switch name {
+	case OutputPeakInsideTemp:
+		return o.PeakInsideTemp
+	case OutputACMinutes:
+		return o.ACMinutes
+	case OutputMonthlyCost:
+		return o.MonthlyCost
+	}
+	return math.NaN()
+}
+
+// Evaluate runs the base scenario with the named parameter values applied.
+func Evaluate(base scenario.Scenario, outsideTemps [840]float64, values map[string]float64) (Outputs, error) {
+	return EvaluateContext(context.Background(), base, outsideTemps, values)
+}
+
+// EvaluateContext is Evaluate with a cancellable simulation.
+func EvaluateContext(ctx context.Context, base scenario.Scenario, outsideTemps [840]float64, values map[string]float64) (Outputs, error) {
+	s := base
+	tariff := 1.0
+
+	if v, ok := values[ParamConductivity]; ok {
+		coeff := s.Coeff
+		if coeff <= 0 {
+			var err error
+			if coeff, err = calc.CalculateCoeffByLayers(s.Layers); err != nil {
+				return Outputs{}, err
+			}
+		}
+		s.Coeff = coeff * math.Max(v, 0)
+	}
+	if v, ok := values[ParamInfiltration]; ok {
+		s.Infiltration = math.Max(v, 0)
+	}
+	if v, ok := values[ParamTariff]; ok {
+		tariff = math.Max(v, 0)
+	}
+	if v, ok := values[ParamOutsideOffset]; ok {
+		for i := range outsideTemps {
+			outsideTemps[i] += v
+		}
+	}
+	if s.AC != nil {
+		ac := *s.AC
+		if v, ok := values[ParamSetTemp]; ok {
+			ac.SetTemp = v
+		}
+		if v, ok := values[ParamACPower]; ok {
+			ac.CoolingPower = -math.Abs(v)
+		}
+		s.AC = &ac
+	}
+
+	r, err := scenario.RunContext(ctx, s, outsideTemps, nil)
+	if err != nil {
+		return Outputs{}, err
+	}
+
+	return Outputs{
+		InsideTemps:    r.InsideTemps,
+		PeakInsideTemp: r.PeakInsideTemp,
+		ACMinutes:      float64(r.ACMinutes),
+		MonthlyCost:    r.MonthlyACCost * tariff,
+	}, nil
+}
+
+// Percentile returns the p-th percentile (0-100) of sorted values using linear interpolation.
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+
+	pos := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	if lo < 0 {
+		return sorted[0]
+	}
+	if hi >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lo] + (pos-float64(lo))*(sorted[hi]-sorted[lo])
+}
+
+func sortedCopy(values []float64) []float64 {
+	out := append([]float64(nil), values...)
+	sort.Float64s(out)
+	return out
+}
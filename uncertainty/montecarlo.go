@@ -0,0 +1,287 @@
+package uncertainty
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"heat-transfer/scenario"
+	"io"
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type MonteCarloParams struct {
+	Samples     int       `json:"samples"`
+	Seed        uint64    `json:"seed"`
+	Percentiles []float64 `json:"percentiles"` // defaults to 10, 50, 90
+	Workers     int       `json:"workers"`     // 0 uses every CPU
+
+	// Progress, when set, is called after every finished sample.
+	Progress func(done, total int) `json:"-"`
+}
+
+// Summary describes the distribution of one scalar output.
+type Summary struct {
+	Mean        float64            `json:"mean"`
+	StdDev      float64            `json:"std_dev"`
+	Min         float64            `json:"min"`
+	Max         float64            `json:"max"`
+	Percentiles map[string]float64 `json:"percentiles"` // keyed "P10" etc.
+}
+
+type MonteCarloResult struct {
+	Samples     int                  `json:"samples"`
+	Seed        uint64               `json:"seed"`
+	Percentiles []float64            `json:"percentiles"`
+	Inputs      []Input              `json:"inputs"`
+	InputValues []map[string]float64 `json:"-"`
+	Outputs     []Outputs            `json:"-"`
+	Failed      int                  `json:"failed"`
+
+	// Bands[i][m] is Percentiles[i] of the inside temperature at minute m
+	Bands [][]float64 `json:"bands"`
+
+	PeakInsideTemp Summary `json:"peak_inside_temp"`
+	ACMinutes      Summary `json:"ac_minutes"`
+	MonthlyCost    Summary `json:"monthly_cost"`
+}
+
+// RunMonteCarlo samples the inputs with a seeded generator and simulates the
+// base scenario once per sample. The same seed always gives the same result.
+func RunMonteCarlo(ctx context.Context, base scenario.Scenario, outsideTemps [840]float64, inputs []Input, p MonteCarloParams) (MonteCarloResult, error) {
+	if err := ValidateInputs(base, inputs); err != nil {
+		return MonteCarloResult{}, err
+	}
+	if p.Samples <= 0 {
+		return MonteCarloResult{}, errors.New("number of samples must be greater than zero")
+	}
+	if len(p.Percentiles) == 0 {
+		p.Percentiles = []float64{10, 50, 90}
+	}
+	if p.Workers <= 0 {
+		p.Workers = runtime.NumCPU()
+	}
+
+	// sample up front so the draw order, and the result, does not depend on scheduling
+	rng := rand.New(rand.NewPCG(p.Seed, p.Seed^0x9e3779b97f4a7c15))
+	values := make([]map[string]float64, p.Samples)
+	for i := range values {
+		values[i] = map[string]float64{}
+		for _, in := range inputs {
+			values[i][in.Name] = in.Distribution.Sample(rng)
+		}
+	}
+
+	outputs, errs, err := evaluateAll(ctx, base, outsideTemps, values, p.Workers, p.Progress)
+	if err != nil {
+		return MonteCarloResult{}, err
+	}
+
+	res := MonteCarloResult{
+		Samples:     p.Samples,
+		Seed:        p.Seed,
+		Percentiles: p.Percentiles,
+		Inputs:      inputs,
+	}
+	for i := range outputs {
+		if errs[i] != nil {
+			res.Failed++
+			continue
+		}
+		res.InputValues = append(res.InputValues, values[i])
+		res.Outputs = append(res.Outputs, outputs[i])
+	}
+	if len(res.Outputs) == 0 {
+		return MonteCarloResult{}, fmt.Errorf("every sample failed: %w", firstError(errs))
+	}
+
+	// per-minute percentile bands
+	minutes := len(res.Outputs[0].InsideTemps)
+	res.Bands = make([][]float64, len(p.Percentiles))
+	for i := range res.Bands {
+		res.Bands[i] = make([]float64, minutes)
+	}
+	column := make([]float64, len(res.Outputs))
+	for m := range minutes {
+		for i, o := range res.Outputs {
+			column[i] = o.InsideTemps[m]
+		}
+		sorted := sortedCopy(column)
+		for i, pct := range p.Percentiles {
+			res.Bands[i][m] = Percentile(sorted, pct)
+		}
+	}
+
+	res.PeakInsideTemp = summarize(res.Outputs, OutputPeakInsideTemp, p.Percentiles)
+	res.ACMinutes = summarize(res.Outputs, OutputACMinutes, p.Percentiles)
+	res.MonthlyCost = summarize(res.Outputs, OutputMonthlyCost, p.Percentiles)
+
+	return res, nil
+}
+
+// evaluateAll runs Evaluate for every value set on a pool of workers.
+func evaluateAll(ctx context.Context, base scenario.Scenario, outsideTemps [840]float64, values []map[string]float64, workers int, progress func(done, total int)) ([]Outputs, []error, error) {
+	outputs := make([]Outputs, len(values))
+	errs := make([]error, len(values))
+	jobs := make(chan int)
+	var done atomic.Int64
+
+	var wg sync.WaitGroup
+	for range min(workers, len(values)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				outputs[i], errs[i] = EvaluateContext(ctx, base, outsideTemps, values[i])
+				if n := done.Add(1); progress != nil {
+					progress(int(n), len(values))
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := range values {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	return outputs, errs, nil
+}
+
+func firstError(errs []error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func summarize(outputs []Outputs, name string, percentiles []float64) Summary {
+	values := make([]float64, len(outputs))
+	sum := 0.0
+	for i, o := range outputs {
+		values[i] = o.Get(name)
+		sum += values[i]
+	}
+	mean := sum / float64(len(values))
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	if len(values) > 1 {
+		variance /= float64(len(values) - 1)
+	}
+
+	sorted := sortedCopy(values)
+	s := Summary{
+		Mean:        mean,
+		StdDev:      math.Sqrt(variance),
+		Min:         sorted[0],
+		Max:         sorted[len(sorted)-1],
+		Percentiles: map[string]float64{},
+	}
+	for _, p := range percentiles {
+		s.Percentiles[percentileLabel(p)] = Percentile(sorted, p)
+	}
+	return s
+}
+
+func percentileLabel(p float64) string {
+	return "P" + strconv.FormatFloat(p, 'f', -1, 64)
+}
+
+// WriteBandsCSV writes the inside temperature percentile bands, one row per minute.
+func (r MonteCarloResult) WriteBandsCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{"minute", "time"}
+	for _, p := range r.Percentiles {
+		header = append(header, percentileLabel(p))
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	if len(r.Bands) > 0 {
+		for m := range r.Bands[0] {
+			rec := []string{strconv.Itoa(m), fmt.Sprintf("%02d:%02d", m/60+5, m%60)}
+			for i := range r.Percentiles {
+				rec = append(rec, strconv.FormatFloat(r.Bands[i][m], 'f', 3, 64))
+			}
+			if err := cw.Write(rec); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteSamplesCSV writes every successful sample's inputs and outputs.
+func (r MonteCarloResult) WriteSamplesCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{"sample"}
+	for _, in := range r.Inputs {
+		header = append(header, in.Name)
+	}
+	header = append(header, outputNames...)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for i, o := range r.Outputs {
+		rec := []string{strconv.Itoa(i)}
+		for _, in := range r.Inputs {
+			rec = append(rec, strconv.FormatFloat(r.InputValues[i][in.Name], 'f', 4, 64))
+		}
+		for _, name := range outputNames {
+			rec = append(rec, strconv.FormatFloat(o.Get(name), 'f', 3, 64))
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func (s Summary) String() string {
+	labels := make([]string, 0, len(s.Percentiles))
+	for label := range s.Percentiles {
+		labels = append(labels, label)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		a, _ := strconv.ParseFloat(labels[i][1:], 64)
+		b, _ := strconv.ParseFloat(labels[j][1:], 64)
+		return a < b
+	})
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "mean %.2f", s.Mean)
+	for _, label := range labels {
+		fmt.Fprintf(&sb, ", %s %.2f", label, s.Percentiles[label])
+	}
+	fmt.Fprintf(&sb, " (min %.2f, max %.2f)", s.Min, s.Max)
+	return sb.String()
+}
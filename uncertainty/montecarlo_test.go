@@ -0,0 +1,139 @@
+package uncertainty
+
+import (
+	"context"
+	"errors"
+	"heat-transfer/scenario/scenariotest"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestPercentile(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4, 5}
+	tests := []struct {
+		p, want float64
+	}{
+		{0, 1}, {10, 1.4}, {25, 2}, {50, 3}, {90, 4.6}, {100, 5},
+	}
+	for _, tt := range tests {
+		if got := Percentile(sorted, tt.p); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("P%g is %g, want %g", tt.p, got, tt.want)
+		}
+	}
+	if got := Percentile([]float64{7}, 90); got != 7 {
+		t.Errorf("a single value is every percentile, got %g", got)
+	}
+	if !math.IsNaN(Percentile(nil, 50)) {
+		t.Error("no values should give NaN")
+	}
+}
+
+func TestRunMonteCarloDeterministic(t *testing.T) {
+	s, outside := scenariotest.Room()
+	inputs := DefaultInputs()
+	run := func(seed uint64, workers int) MonteCarloResult {
+		res, err := RunMonteCarlo(context.Background(), s, outside, inputs, MonteCarloParams{Samples: 40, Seed: seed, Workers: workers})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	a, b := run(7, 1), run(7, 4)
+	if !reflect.DeepEqual(a.Bands, b.Bands) || !reflect.DeepEqual(a.MonthlyCost, b.MonthlyCost) {
+		t.Error("the same seed gave different results with other worker counts")
+	}
+	if c := run(8, 1); reflect.DeepEqual(a.MonthlyCost, c.MonthlyCost) {
+		t.Error("another seed gave the same result")
+	}
+
+	if len(a.Bands) != 3 || len(a.Bands[0]) != 840 {
+		t.Fatalf("%d bands of %d minutes, want P10, P50 and P90 of 840", len(a.Bands), len(a.Bands[0]))
+	}
+	for m := range a.Bands[0] {
+		if a.Bands[0][m] > a.Bands[1][m] || a.Bands[1][m] > a.Bands[2][m] {
+			t.Fatalf("minute %d: bands %g, %g, %g out of order", m, a.Bands[0][m], a.Bands[1][m], a.Bands[2][m])
+		}
+	}
+	for _, sum := range []Summary{a.PeakInsideTemp, a.ACMinutes, a.MonthlyCost} {
+		if sum.Min > sum.Percentiles["P10"] || sum.Percentiles["P10"] > sum.Percentiles["P90"] || sum.Percentiles["P90"] > sum.Max {
+			t.Errorf("summary %+v out of order", sum)
+		}
+	}
+}
+
+func TestRunMonteCarloTariff(t *testing.T) {
+	// only the tariff varies, so the cost is the nominal cost times a
+	// uniform draw and the temperatures do not spread at all
+	s, outside := scenariotest.Room()
+	nominal, err := Evaluate(s, outside, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nominal.MonthlyCost <= 0 {
+		t.Fatalf("nominal cost %g, the AC should run", nominal.MonthlyCost)
+	}
+
+	inputs := []Input{{Name: ParamTariff, Distribution: Distribution{Kind: Uniform, Min: 1, Max: 1.3}}}
+	res, err := RunMonteCarlo(context.Background(), s, outside, inputs, MonteCarloParams{Samples: 2000, Seed: 1, Percentiles: []float64{5, 50, 95}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cost := res.MonthlyCost
+	if rel := cost.Mean/nominal.MonthlyCost - 1.15; math.Abs(rel) > 0.01 {
+		t.Errorf("mean cost %g is %+.3f off 1.15 times the nominal %g", cost.Mean, rel, nominal.MonthlyCost)
+	}
+	if cost.Min < nominal.MonthlyCost-1e-9 || cost.Max > 1.3*nominal.MonthlyCost+1e-9 {
+		t.Errorf("cost from %g to %g, outside 1 to 1.3 times %g", cost.Min, cost.Max, nominal.MonthlyCost)
+	}
+	if p := cost.Percentiles["P95"] / nominal.MonthlyCost; math.Abs(p-1.285) > 0.01 {
+		t.Errorf("P95 is %g times the nominal cost, want about 1.285", p)
+	}
+	if res.PeakInsideTemp.StdDev > 1e-9 {
+		t.Errorf("peak temperature spread %g, the tariff does not change it", res.PeakInsideTemp.StdDev)
+	}
+	for m := range res.Bands[0] {
+		if res.Bands[0][m] != res.Bands[2][m] {
+			t.Fatalf("minute %d: bands differ without a thermal input", m)
+		}
+	}
+}
+
+func TestRunMonteCarloErrors(t *testing.T) {
+	s, outside := scenariotest.Room()
+	tests := []struct {
+		name   string
+		inputs []Input
+		p      MonteCarloParams
+	}{
+		{"no inputs", nil, MonteCarloParams{Samples: 10}},
+		{"misspelled input", []Input{{Name: "conductivty", Distribution: Distribution{Kind: Fixed, Mean: 1}}}, MonteCarloParams{Samples: 10}},
+		{"input twice", []Input{{Name: ParamTariff, Distribution: Distribution{Kind: Fixed, Mean: 1}}, {Name: ParamTariff, Distribution: Distribution{Kind: Fixed, Mean: 1}}}, MonteCarloParams{Samples: 10}},
+		{"no samples", DefaultInputs(), MonteCarloParams{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := RunMonteCarlo(context.Background(), s, outside, tt.inputs, tt.p); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	// without an AC the set point and power have nothing to act on
+	noAC := s
+	noAC.AC = nil
+	for _, name := range []string{ParamSetTemp, ParamACPower} {
+		inputs := []Input{{Name: name, Distribution: Distribution{Kind: Uniform, Min: 24, Max: 26}}}
+		if _, err := RunMonteCarlo(context.Background(), noAC, outside, inputs, MonteCarloParams{Samples: 10}); err == nil {
+			t.Errorf("%s should be refused without an AC", name)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := RunMonteCarlo(ctx, s, outside, DefaultInputs(), MonteCarloParams{Samples: 100}); !errors.Is(err, context.Canceled) {
+		t.Errorf("error %v, want the cancellation", err)
+	}
+}
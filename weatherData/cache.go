@@ -0,0 +1,206 @@
+package weatherdata
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache keeps raw API responses on disk so repeated runs, workshops sharing a
+// quota and restarts without a network connection reuse earlier answers.
+type Cache struct {
+	Dir string
+
+	GeocodeTTL  time.Duration // coordinates of a place name
+	ForecastTTL time.Duration // onecall forecasts, refreshed hourly upstream
+
+	// MaxStale is how old an expired entry may be and still be used when the
+	// API cannot be reached. Forecasts only cover the next 48 hours.
+	MaxStale time.Duration
+
+	mu sync.Mutex
+}
+
+// DefaultCache is used by every fetch in this package. Nil disables caching.
+var DefaultCache = NewCache(DefaultCacheDir())
+
+// ReportCache is told when a cached response stands in for a failed request
+// or a response could not be cached, nil ignores it.
+var ReportCache func(err error)
+
+// httpClient gives up on APIs that stop answering, so the cache can step in.
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+func NewCache(dir string) *Cache {
+	if dir == "" {
+		return nil
+	}
+	return &Cache{
+		Dir:         dir,
+		GeocodeTTL:  30 * 24 * time.Hour,
+		ForecastTTL: time.Hour,
+		MaxStale:    48 * time.Hour,
+	}
+}
+
+// DefaultCacheDir is heat-transfer/weather under the user cache directory,
+// empty when there is none.
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "heat-transfer", "weather")
+}
+
+type cacheEntry struct {
+	Key     string          `json:"key"`
+	Fetched time.Time       `json:"fetched"`
+	Body    json.RawMessage `json:"body"`
+}
+
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	kind, _, _ := strings.Cut(key, ":")
+	return filepath.Join(c.Dir, kind+"-"+hex.EncodeToString(sum[:8])+".json")
+}
+
+func (c *Cache) load(key string) (cacheEntry, bool) {
+	buf, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return cacheEntry{}, false
+	}
+	var e cacheEntry
+	if err := json.Unmarshal(buf, &e); err != nil || e.Key != key {
+		return cacheEntry{}, false
+	}
+	return e, true
+}
+
+// store writes through a temporary file so readers never see half an entry.
+func (c *Cache) store(key string, body []byte) error {
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return err
+	}
+	buf, err := json.Marshal(cacheEntry{Key: key, Fetched: time.Now(), Body: body})
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(c.Dir, "tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), c.path(key))
+}
+
+// Clear removes every cached response.
+func (c *Cache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	err := os.RemoveAll(c.Dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+const (
+	geocodeTTL = iota
+	forecastTTL
+)
+
+// ttl picks one of the configured lifetimes, c may be nil.
+func (c *Cache) ttl(kind int) time.Duration {
+	if c == nil {
+		return 0
+	}
+	if kind == geocodeTTL {
+		return c.GeocodeTTL
+	}
+	return c.ForecastTTL
+}
+
+// fetch returns the body stored under key while it is younger than ttl, and
+// otherwise GETs url and stores the answer. ttl < 0 never expires. A stale
+// entry is returned when the request fails.
+func (c *Cache) fetch(key, rawURL string, ttl time.Duration) ([]byte, error) {
+	if c == nil {
+		return get(rawURL)
+	}
+
+	c.mu.Lock()
+	e, ok := c.load(key)
+	c.mu.Unlock()
+	age := time.Since(e.Fetched)
+	if ok && (ttl < 0 || age < ttl) {
+		return e.Body, nil
+	}
+
+	body, err := get(rawURL)
+	if err != nil {
+		if ok && (ttl < 0 || age < ttl+c.MaxStale) {
+			report(fmt.Errorf("%w, using cached response from %s", err, e.Fetched.Format(time.DateTime)))
+			return e.Body, nil
+		}
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.store(key, body); err != nil {
+		// the answer is still good, only the next run pays for it
+		report(fmt.Errorf("weather cache: %w", err))
+	}
+	return body, nil
+}
+
+func report(err error) {
+	if ReportCache != nil {
+		ReportCache(err)
+	}
+}
+
+func get(rawURL string) ([]byte, error) {
+	resp, err := httpClient.Get(rawURL)
+	if err != nil {
+		// the URL carries the API key, keep it out of logs and responses
+		var ue *url.Error
+		if errors.As(err, &ue) {
+			return nil, ue.Err
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// normalizeQuery makes "Khon+Kaen,+TH" and "khon kaen, th" share a cache entry.
+func normalizeQuery(query string) string {
+	query = strings.ReplaceAll(query, "+", " ")
+	return strings.ToLower(strings.Join(strings.Fields(query), " "))
+}
@@ -0,0 +1,48 @@
+package weatherdata
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCacheFetch(t *testing.T) {
+	var up bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !up {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"fresh":true}`))
+	}))
+	defer srv.Close()
+
+	var reports []error
+	t.Cleanup(func() { ReportCache = nil })
+	ReportCache = func(err error) { reports = append(reports, err) }
+
+	c := NewCache(t.TempDir())
+	up = true
+	body, err := c.fetch("forecast:test", srv.URL, time.Hour)
+	if err != nil || string(body) != `{"fresh":true}` {
+		t.Fatalf("first fetch %q, %v", body, err)
+	}
+
+	// expired but within MaxStale, the API is down
+	up = false
+	body, err = c.fetch("forecast:test", srv.URL, 0)
+	if err != nil || string(body) != `{"fresh":true}` {
+		t.Fatalf("stale fetch %q, %v", body, err)
+	}
+	if len(reports) != 1 || !strings.Contains(reports[0].Error(), "using cached response") {
+		t.Errorf("reports %v, want the stale fallback", reports)
+	}
+
+	// beyond MaxStale the error comes through
+	c.MaxStale = -time.Hour
+	if _, err := c.fetch("forecast:test", srv.URL, 0); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("error %v, want the API failure", err)
+	}
+}
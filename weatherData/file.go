@@ -0,0 +1,95 @@
+package weatherdata
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"heat-transfer/interop"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LoadHourlyTemperatures reads hourly outside temperatures from 05:00 to 19:00
+// from a CSV file. Rows are either "temp" or "time,temp"; a header row is skipped.
+func LoadHourlyTemperatures(path string) ([]float64, error) {
+	_, temps, err := LoadTemperatureSeries(path)
+	return temps, err
+}
+
+// LoadTemperatureSeries reads a weather file with the minute after 05:00 of
+// each temperature. Rows of "time,temp" with an HH:MM time may be spaced
+// unevenly; rows without a time are taken as consecutive hours.
+func LoadTemperatureSeries(path string) ([]float64, []float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var minutes, temps []float64
+	timed := true
+	for i, rec := range records {
+		if len(rec) == 0 {
+			continue
+		}
+		field := strings.TrimSpace(rec[len(rec)-1])
+		v, err := strconv.ParseFloat(field, 64)
+		if field == "" && len(rec) > 1 {
+			// logged time without a value, left for the quality check
+			v, err = math.NaN(), nil
+		}
+		if err != nil {
+			if i == 0 {
+				// header
+				continue
+			}
+			return nil, nil, fmt.Errorf("%s: line %d: %w", path, i+1, err)
+		}
+		temps = append(temps, v)
+
+		at, err := time.Parse("15:04", strings.TrimSpace(rec[0]))
+		if len(rec) < 2 || err != nil {
+			timed = false
+			continue
+		}
+		minutes = append(minutes, float64(at.Hour()*60+at.Minute()-5*60))
+	}
+
+	if len(temps) == 0 {
+		return nil, nil, fmt.Errorf("%s: %w", path, errors.New("no temperatures found"))
+	}
+	if !timed {
+		minutes = interop.HourlyMinutes(len(temps))
+	}
+	return minutes, temps, nil
+}
+
+// GetTemperatureFromFile loads a weather file, checks it against
+// quality.Default and interpolates it to one value per minute with
+// interop.Default.
+func GetTemperatureFromFile(path string) ([840]float64, error) {
+	minutes, temps, err := LoadTemperatureSeries(path)
+	if err != nil {
+		return [840]float64{}, err
+	}
+	minutes, temps, err = checkSeries(path, minutes, temps)
+	if err != nil {
+		return [840]float64{}, fmt.Errorf("%s: %w", path, err)
+	}
+	day, err := interop.Default.Day(minutes, temps)
+	if err != nil {
+		return [840]float64{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return day, nil
+}
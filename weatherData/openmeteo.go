@@ -0,0 +1,224 @@
+package weatherdata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"heat-transfer/calc"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// OpenMeteo uses the keyless Open-Meteo geocoding, forecast and archive APIs.
+// The base URLs can point at a mirror or a self-hosted instance.
+type OpenMeteo struct {
+	GeocodingURL string
+	ForecastURL  string
+	ArchiveURL   string
+}
+
+func NewOpenMeteo() OpenMeteo {
+	return OpenMeteo{
+		GeocodingURL: "https://geocoding-api.open-meteo.com/v1/search",
+		ForecastURL:  "https://api.open-meteo.com/v1/forecast",
+		ArchiveURL:   "https://archive-api.open-meteo.com/v1/archive",
+	}
+}
+
+func (p OpenMeteo) Name() string { return ProviderOpenMeteo }
+
+// the archive has no UV index, it stays zero for historical days
+const (
+	openMeteoForecastVars = "temperature_2m,relative_humidity_2m,dew_point_2m,cloud_cover,wind_speed_10m,wind_direction_10m,uv_index,pressure_msl"
+	openMeteoArchiveVars  = "temperature_2m,relative_humidity_2m,dew_point_2m,cloud_cover,wind_speed_10m,wind_direction_10m,pressure_msl"
+)
+
+type openMeteoPlace struct {
+	Name        string  `json:"name"`
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+	CountryCode string  `json:"country_code"`
+}
+
+// OpenMeteoData is the response of the forecast and archive endpoints. Values
+// are null where the archive has no data yet.
+type OpenMeteoData struct {
+	UTCOffsetSeconds int `json:"utc_offset_seconds"`
+	Hourly           struct {
+		Time      []string   `json:"time"` // local, 2006-01-02T15:04
+		Temp      []*float64 `json:"temperature_2m"`
+		Humidity  []*float64 `json:"relative_humidity_2m"`
+		DewPoint  []*float64 `json:"dew_point_2m"`
+		Clouds    []*float64 `json:"cloud_cover"`
+		WindSpeed []*float64 `json:"wind_speed_10m"`
+		WindDeg   []*float64 `json:"wind_direction_10m"`
+		UVI       []*float64 `json:"uv_index"`
+		Pressure  []*float64 `json:"pressure_msl"`
+	} `json:"hourly"`
+}
+
+// geocode resolves "name" or "name, CC" where CC is an ISO country code.
+func (p OpenMeteo) geocode(query string) (float64, float64, error) {
+	query = normalizeQuery(query)
+	name, country, _ := strings.Cut(query, ",")
+	v := url.Values{
+		"name":     {strings.TrimSpace(name)},
+		"count":    {"1"},
+		"language": {"en"},
+		"format":   {"json"},
+	}
+	if country = strings.TrimSpace(country); country != "" {
+		v.Set("countryCode", strings.ToUpper(country))
+	}
+
+	body, err := DefaultCache.fetch("openmeteo-geocode:"+query, p.GeocodingURL+"?"+v.Encode(), DefaultCache.ttl(geocodeTTL))
+	if err != nil {
+		return 0, 0, fmt.Errorf("geocoding API error: %w", err)
+	}
+	var res struct {
+		Results []openMeteoPlace `json:"results"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return 0, 0, err
+	}
+	if len(res.Results) == 0 {
+		return 0, 0, errors.New("no geocoding results found")
+	}
+	place := res.Results[0]
+	return place.Latitude, place.Longitude, nil
+}
+
+func (p OpenMeteo) Forecast(query string) (calc.Weather, error) {
+	lat, lon, err := p.geocode(query)
+	if err != nil {
+		return calc.Weather{}, err
+	}
+
+	v := p.params(lat, lon, openMeteoForecastVars)
+	v.Set("forecast_days", "2")
+	key := fmt.Sprintf("openmeteo-forecast:%.2f,%.2f", lat, lon)
+	data, err := p.fetch(key, p.ForecastURL, v, DefaultCache.ttl(forecastTTL))
+	if err != nil {
+		return calc.Weather{}, fmt.Errorf("forecast API error: %w", err)
+	}
+
+	// same day as the OpenWeatherMap forecast
+	today := now().In(time.FixedZone("local", data.UTCOffsetSeconds))
+	day := today
+	if today.Hour() >= 7 {
+		day = today.Add(24 * time.Hour)
+	}
+
+	hours, start, err := data.day(day)
+	if err != nil {
+		return calc.Weather{}, err
+	}
+	return InterpolateDay(query, start, hours)
+}
+
+func (p OpenMeteo) Historical(query string, t time.Time) (calc.Weather, error) {
+	lat, lon, err := p.geocode(query)
+	if err != nil {
+		return calc.Weather{}, err
+	}
+
+	// the date is taken at the place; requesting the day either side of t
+	// covers every time zone
+	v := p.params(lat, lon, openMeteoArchiveVars)
+	v.Set("start_date", t.Add(-24*time.Hour).UTC().Format(time.DateOnly))
+	v.Set("end_date", t.Add(24*time.Hour).UTC().Format(time.DateOnly))
+	// the archive fills in the last few days late, older days never change
+	ttl := 24 * time.Hour
+	if now().Sub(t) > 7*24*time.Hour {
+		ttl = -1
+	}
+	key := fmt.Sprintf("openmeteo-archive:%.2f,%.2f:%s", lat, lon, t.UTC().Format(time.DateOnly))
+	data, err := p.fetch(key, p.ArchiveURL, v, ttl)
+	if err != nil {
+		return calc.Weather{}, fmt.Errorf("historical API error: %w", err)
+	}
+
+	hours, start, err := data.day(t.In(time.FixedZone("local", data.UTCOffsetSeconds)))
+	if err != nil {
+		return calc.Weather{}, err
+	}
+	return InterpolateDay(query, start, hours)
+}
+
+func (p OpenMeteo) params(lat, lon float64, vars string) url.Values {
+	return url.Values{
+		"latitude":        {fmt.Sprintf("%.4f", lat)},
+		"longitude":       {fmt.Sprintf("%.4f", lon)},
+		"hourly":          {vars},
+		"timezone":        {"auto"},
+		"wind_speed_unit": {"ms"},
+	}
+}
+
+func (p OpenMeteo) fetch(key, base string, v url.Values, ttl time.Duration) (OpenMeteoData, error) {
+	body, err := DefaultCache.fetch(key, base+"?"+v.Encode(), ttl)
+	if err != nil {
+		return OpenMeteoData{}, err
+	}
+
+	var data OpenMeteoData
+	if err := json.Unmarshal(body, &data); err != nil {
+		return OpenMeteoData{}, err
+	}
+	if len(data.Hourly.Time) == 0 {
+		return OpenMeteoData{}, errors.New("response has no hourly data")
+	}
+	return data, nil
+}
+
+// day picks 05:00 to 19:00 on the date of day and returns them with 05:00.
+// Hours without a temperature are left out for the quality check, other
+// missing variables repeat the previous hour.
+func (d OpenMeteoData) day(day time.Time) ([]HourlyForecast, time.Time, error) {
+	loc := time.FixedZone("local", d.UTCOffsetSeconds)
+	start := time.Date(day.Year(), day.Month(), day.Day(), 5, 0, 0, 0, loc)
+	end := time.Date(day.Year(), day.Month(), day.Day(), 19, 0, 0, 0, loc)
+
+	value := func(values []*float64, i int, prev float64) float64 {
+		if i < len(values) && values[i] != nil {
+			return *values[i]
+		}
+		return prev
+	}
+
+	h := d.Hourly
+	hours := make([]HourlyForecast, 0, 15)
+	var prev HourlyForecast
+	for i, ts := range h.Time {
+		at, err := time.ParseInLocation("2006-01-02T15:04", ts, loc)
+		if err != nil {
+			return nil, start, err
+		}
+		if at.Before(start) || at.After(end) {
+			continue
+		}
+		if i >= len(h.Temp) || h.Temp[i] == nil {
+			continue
+		}
+
+		cur := HourlyForecast{
+			Dt:        at.Unix(),
+			Temp:      *h.Temp[i],
+			Humidity:  value(h.Humidity, i, prev.Humidity),
+			DewPoint:  value(h.DewPoint, i, prev.DewPoint),
+			Clouds:    value(h.Clouds, i, prev.Clouds),
+			WindSpeed: value(h.WindSpeed, i, prev.WindSpeed),
+			WindDeg:   value(h.WindDeg, i, prev.WindDeg),
+			UVI:       value(h.UVI, i, prev.UVI),
+			Pressure:  value(h.Pressure, i, prev.Pressure),
+		}
+		hours = append(hours, cur)
+		prev = cur
+	}
+
+	if len(hours) == 0 {
+		return nil, start, fmt.Errorf("no hourly data for %s", start.Format(time.DateOnly))
+	}
+	return hours, start, nil
+}
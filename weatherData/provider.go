@@ -0,0 +1,56 @@
+package weatherdata
+
+import (
+	"fmt"
+	"heat-transfer/calc"
+	"time"
+)
+
+// Provider names.
+const (
+	ProviderOpenWeatherMap = "openweathermap"
+	ProviderOpenMeteo      = "openmeteo"
+)
+
+// Provider is a source of forecast and observed days for a place name such
+// as "Khon Kaen, TH".
+type Provider interface {
+	Name() string
+
+	// Forecast returns today, or tomorrow once it is past 07:00 at the place.
+	Forecast(query string) (calc.Weather, error)
+
+	// Historical returns the observed day of t in the place's time zone.
+	Historical(query string, t time.Time) (calc.Weather, error)
+}
+
+// OpenWeatherMap uses the One Call 3.0 API, which needs an API key.
+type OpenWeatherMap struct {
+	APIKey string
+}
+
+func (p OpenWeatherMap) Name() string { return ProviderOpenWeatherMap }
+
+func (p OpenWeatherMap) Forecast(query string) (calc.Weather, error) {
+	return GetCityWeatherForecastNow(query, p.APIKey)
+}
+
+func (p OpenWeatherMap) Historical(query string, t time.Time) (calc.Weather, error) {
+	return GetCityWeatherHistorical(query, p.APIKey, t)
+}
+
+// NewProvider returns the named provider. apiKey is only used by
+// OpenWeatherMap.
+func NewProvider(name, apiKey string) (Provider, error) {
+	switch name {
+	case ProviderOpenWeatherMap:
+		if apiKey == "" {
+			return nil, fmt.Errorf("%s needs an API key", name)
+		}
+		return OpenWeatherMap{APIKey: apiKey}, nil
+	case ProviderOpenMeteo:
+		return NewOpenMeteo(), nil
+	default:
+		return nil, fmt.Errorf("unknown weather provider %q, use %s or %s", name, ProviderOpenWeatherMap, ProviderOpenMeteo)
+	}
+}
@@ -0,0 +1,65 @@
+package weatherdata
+
+import (
+	"fmt"
+	"heat-transfer/calc"
+	"heat-transfer/quality"
+	"slices"
+	"time"
+)
+
+// ReportQuality is told about every weather source whose temperatures needed
+// fixing or have gaps, nil ignores them.
+var ReportQuality func(source string, r quality.Report)
+
+// checkSeries runs quality.Default over a temperature series.
+func checkSeries(source string, minutes, temps []float64) ([]float64, []float64, error) {
+	minutes, temps, report, err := quality.Check(minutes, temps, quality.Default)
+	if len(report.Issues) > 0 && ReportQuality != nil {
+		ReportQuality(source, report)
+	}
+	return minutes, temps, err
+}
+
+// InterpolateDay places the hourly records of source at their time after
+// start, 05:00 on the day, checks the temperatures against quality.Default and
+// interpolates every variable with interop.Default. Records whose temperature
+// was removed are left out of the other variables too.
+func InterpolateDay(source string, start time.Time, hours []HourlyForecast) (calc.Weather, error) {
+	minutes := make([]float64, len(hours))
+	for i, h := range hours {
+		minutes[i] = float64(h.Dt-start.Unix()) / 60
+	}
+	temps := make([]float64, len(hours))
+	for i, h := range hours {
+		temps[i] = h.Temp
+	}
+
+	tempMinutes, temps, err := checkSeries(source, minutes, temps)
+	if err != nil {
+		return calc.Weather{}, fmt.Errorf("%s on %s: %w", source, start.Format(time.DateOnly), err)
+	}
+
+	// records still in the checked series, in time order, one per minute
+	var kept []HourlyForecast
+	var keptMinutes []float64
+	order := make([]int, len(hours))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return int(hours[a].Dt - hours[b].Dt) })
+	for _, i := range order {
+		m := minutes[i]
+		if _, ok := slices.BinarySearch(tempMinutes, m); !ok {
+			continue
+		}
+		if n := len(keptMinutes); n > 0 && keptMinutes[n-1] == m {
+			continue
+		}
+		kept = append(kept, hours[i])
+		keptMinutes = append(keptMinutes, m)
+	}
+	w := interpolateWeather(kept, keptMinutes, tempMinutes, temps)
+	w.Start = start
+	return w, nil
+}
@@ -4,16 +4,24 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"heat-transfer/calc"
 	"heat-transfer/interop"
-	"io"
-	"net/http"
-	"strings"
+	"math"
+	"net/url"
 	"time"
 )
 
+// HourlyForecast is one hour of the One Call API, forecast or observed.
 type HourlyForecast struct {
-	Dt   int64   `json:"dt"`
-	Temp float64 `json:"temp"`
+	Dt        int64   `json:"dt"`
+	Temp      float64 `json:"temp"`       // °C
+	Humidity  float64 `json:"humidity"`   // %
+	DewPoint  float64 `json:"dew_point"`  // °C
+	Clouds    float64 `json:"clouds"`     // %
+	WindSpeed float64 `json:"wind_speed"` // m/s
+	WindDeg   float64 `json:"wind_deg"`   // degrees
+	UVI       float64 `json:"uvi"`
+	Pressure  float64 `json:"pressure"` // hPa
 }
 
 type ForecastData struct {
@@ -28,63 +36,69 @@ type GeoLocation struct {
 	Country string  `json:"country"`
 }
 
-func GetCityTemperatureForecastNow(query, apiKey string) ([840]float64, error) {
-	query = strings.ReplaceAll(query, " ", "%20")
+// OpenWeatherMap endpoints, variables so tests can serve recorded responses.
+var (
+	owmGeocodingURL = "http://api.openweathermap.org/geo/1.0/direct"
+	owmOneCallURL   = "https://api.openweathermap.org/data/3.0/onecall"
+)
 
-	geoURL := fmt.Sprintf("http://api.openweathermap.org/geo/1.0/direct?q=%s&limit=1&appid=%s", query, apiKey)
-	fmt.Println("Geocoding URL:", geoURL)
-	resp, err := http.Get(geoURL)
-	if err != nil {
-		return [840]float64{}, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return [840]float64{}, fmt.Errorf("geocoding API error: %s", resp.Status)
-	}
-	geoBody, err := io.ReadAll(resp.Body)
+// now is the clock deciding which day is forecast and which has been observed.
+var now = time.Now
+
+// geocode resolves a place name to coordinates.
+func geocode(query, apiKey string) (float64, float64, error) {
+	geoURL := fmt.Sprintf("%s?q=%s&limit=1&appid=%s", owmGeocodingURL, url.QueryEscape(normalizeQuery(query)), apiKey)
+	geoBody, err := DefaultCache.fetch("geocode:"+normalizeQuery(query), geoURL, DefaultCache.ttl(geocodeTTL))
 	if err != nil {
-		return [840]float64{}, err
+		return 0, 0, fmt.Errorf("geocoding API error: %w", err)
 	}
 	var geoResults []GeoLocation
 	if err := json.Unmarshal(geoBody, &geoResults); err != nil {
-		return [840]float64{}, err
+		return 0, 0, err
 	}
 	if len(geoResults) == 0 {
-		return [840]float64{}, errors.New("no geocoding results found")
+		return 0, 0, errors.New("no geocoding results found")
 	}
 	lat, lon := geoResults[0].Lat, geoResults[0].Lon
-	fmt.Println("Coordinates:", lat, lon)
+	return lat, lon, nil
+}
 
-	forecastURL := fmt.Sprintf("https://api.openweathermap.org/data/3.0/onecall?lat=%f&lon=%f&units=metric&appid=%s", lat, lon, apiKey)
-	fmt.Printf("Forecast URL: %s\n", forecastURL)
-	resp, err = http.Get(forecastURL)
+func GetCityTemperatureForecastNow(query, apiKey string) ([840]float64, error) {
+	w, err := GetCityWeatherForecastNow(query, apiKey)
+	return w.Temp, err
+}
+
+// GetCityWeatherForecastNow returns every forecast variable for today, or for
+// tomorrow once it is past 07:00 at the place.
+func GetCityWeatherForecastNow(query, apiKey string) (calc.Weather, error) {
+	lat, lon, err := geocode(query, apiKey)
 	if err != nil {
-		return [840]float64{}, err
+		return calc.Weather{}, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return [840]float64{}, fmt.Errorf("forecast API error: %s", resp.Status)
-	}
-	forecastBody, err := io.ReadAll(resp.Body)
+
+	forecastURL := fmt.Sprintf("%s?lat=%f&lon=%f&units=metric&appid=%s", owmOneCallURL, lat, lon, apiKey)
+	// onecall covers the next 48 hours from the time of the request
+	key := fmt.Sprintf("forecast:%.2f,%.2f", lat, lon)
+	forecastBody, err := DefaultCache.fetch(key, forecastURL, DefaultCache.ttl(forecastTTL))
 	if err != nil {
-		return [840]float64{}, err
+		return calc.Weather{}, fmt.Errorf("forecast API error: %w", err)
 	}
 	var forecast ForecastData
 	if err := json.Unmarshal(forecastBody, &forecast); err != nil {
-		return [840]float64{}, err
+		return calc.Weather{}, err
 	}
 
 	loc := time.FixedZone("local", forecast.TimezoneOffset)
 
-	now := time.Now().In(loc)
-	targetDate := now
-	if now.Hour() >= 7 {
-		targetDate = now.Add(24 * time.Hour)
+	today := now().In(loc)
+	targetDate := today
+	if today.Hour() >= 7 {
+		targetDate = today.Add(24 * time.Hour)
 	}
 	targetDayStart := time.Date(targetDate.Year(), targetDate.Month(), targetDate.Day(), 5, 0, 0, 0, loc)
 	targetDayEnd := time.Date(targetDate.Year(), targetDate.Month(), targetDate.Day(), 19, 0, 0, 0, loc)
 
-	hourlyTemps := make([]float64, 0)
+	hours := make([]HourlyForecast, 0)
 
 	for _, hourly := range forecast.Hourly {
 		hourlyTime := time.Unix(hourly.Dt, 0).In(loc)
@@ -92,81 +106,157 @@ func GetCityTemperatureForecastNow(query, apiKey string) ([840]float64, error) {
 			continue
 		}
 
-		hourlyTemps = append(hourlyTemps, hourly.Temp)
+		hours = append(hours, hourly)
 	}
 
-	// interpolate
-
-	return interop.MovingWindowInterpolateTemperature(hourlyTemps), nil
+	return InterpolateDay(query, targetDayStart, hours)
 }
 
-// doesnt work
-func GetCityTemperatureForecastHistorical(query, apiKey string, t time.Time) ([840]float64, error) {
-	query = strings.ReplaceAll(query, " ", "%20")
+// TimeMachineData is the response of the onecall timemachine endpoint, which
+// holds a single observation per request.
+type TimeMachineData struct {
+	Data           []HourlyForecast `json:"data"`
+	TimezoneOffset int              `json:"timezone_offset"`
+}
 
-	geoURL := fmt.Sprintf("http://api.openweathermap.org/geo/1.0/direct?q=%s&limit=1&appid=%s", query, apiKey)
-	fmt.Println("Geocoding URL:", geoURL)
-	resp, err := http.Get(geoURL)
+// timeMachine fetches the observation closest to t.
+func timeMachine(lat, lon float64, t time.Time, apiKey string) (TimeMachineData, error) {
+	historicalURL := fmt.Sprintf("%s/timemachine?lat=%f&lon=%f&dt=%d&units=metric&appid=%s", owmOneCallURL, lat, lon, t.Unix(), apiKey)
+	// past weather does not change, so the entry never expires
+	key := fmt.Sprintf("timemachine:%.2f,%.2f:%d", lat, lon, t.Unix())
+	body, err := DefaultCache.fetch(key, historicalURL, -1)
 	if err != nil {
-		return [840]float64{}, err
+		return TimeMachineData{}, fmt.Errorf("historical API error: %w", err)
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return [840]float64{}, fmt.Errorf("geocoding API error: %s", resp.Status)
+
+	var tm TimeMachineData
+	if err := json.Unmarshal(body, &tm); err != nil {
+		return TimeMachineData{}, err
 	}
-	geoBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return [840]float64{}, err
+	if len(tm.Data) == 0 {
+		return TimeMachineData{}, fmt.Errorf("no historical data for %s", t.UTC().Format(time.DateTime))
 	}
-	var geoResults []GeoLocation
-	if err := json.Unmarshal(geoBody, &geoResults); err != nil {
-		return [840]float64{}, err
-	}
-	if len(geoResults) == 0 {
-		return [840]float64{}, errors.New("no geocoding results found")
+	return tm, nil
+}
+
+// GetCityHourlyHistorical returns the observations from 05:00 to 19:00 local
+// time on the day of t at the place, one per hour. The day is taken in the
+// place's time zone.
+func GetCityHourlyHistorical(query, apiKey string, t time.Time) ([]HourlyForecast, error) {
+	lat, lon, err := geocode(query, apiKey)
+	if err != nil {
+		return nil, err
 	}
-	lat, lon := geoResults[0].Lat, geoResults[0].Lon
-	fmt.Println("Coordinates:", lat, lon)
 
-	// doesnt work
-	historicalURL := fmt.Sprintf("https://api.openweathermap.org/data/3.0/onecall/timemachine?lat=%f&lon=%f&dt=%d&units=metric&appid=%s", lat, lon, t.Unix(), apiKey)
-	fmt.Printf("Historical URL: %s\n", historicalURL)
-	resp, err = http.Get(historicalURL)
+	// one lookup to learn the time zone, the hours below usually hit it again
+	probe, err := timeMachine(lat, lon, t.Truncate(time.Hour), apiKey)
 	if err != nil {
-		return [840]float64{}, err
+		return nil, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return [840]float64{}, fmt.Errorf("historical API error: %s", resp.Status)
+	loc := time.FixedZone("local", probe.TimezoneOffset)
+	day := t.In(loc)
+
+	hours := make([]HourlyForecast, 0, 15)
+	for hour := 5; hour <= 19; hour++ {
+		at := time.Date(day.Year(), day.Month(), day.Day(), hour, 0, 0, 0, loc)
+		if at.After(now()) {
+			return nil, fmt.Errorf("%s %02d:00 has not been observed yet", day.Format(time.DateOnly), hour)
+		}
+
+		tm, err := timeMachine(lat, lon, at, apiKey)
+		if err != nil {
+			return nil, err
+		}
+		hours = append(hours, tm.Data[0])
 	}
-	historicalBody, err := io.ReadAll(resp.Body)
+
+	return hours, nil
+}
+
+// GetCityTemperatureForecastHistorical returns the observed outside
+// temperatures of a past day, interpolated to minutes like the forecast.
+func GetCityTemperatureForecastHistorical(query, apiKey string, t time.Time) ([840]float64, error) {
+	w, err := GetCityWeatherHistorical(query, apiKey, t)
+	return w.Temp, err
+}
+
+// GetCityWeatherHistorical returns every observed variable of a past day.
+func GetCityWeatherHistorical(query, apiKey string, t time.Time) (calc.Weather, error) {
+	hours, err := GetCityHourlyHistorical(query, apiKey, t)
 	if err != nil {
-		return [840]float64{}, err
-	}
-	var forecast ForecastData
-	if err := json.Unmarshal(historicalBody, &forecast); err != nil {
-		return [840]float64{}, err
+		return calc.Weather{}, err
 	}
 
-	// local time
-	loc := time.FixedZone("local", forecast.TimezoneOffset)
+	// the first hour is 05:00
+	return InterpolateDay(query, time.Unix(hours[0].Dt, 0), hours)
+}
 
-	targetDate := t.In(loc)
-	targetDayStart := time.Date(targetDate.Year(), targetDate.Month(), targetDate.Day(), 5, 0, 0, 0, loc)
-	targetDayEnd := time.Date(targetDate.Year(), targetDate.Month(), targetDate.Day(), 19, 0, 0, 0, loc)
+// InterpolateHourly spreads hourly records from 05:00 over the minutes of the
+// day with interop.Default, placing each record at its timestamp. It does no
+// quality checks, see InterpolateDay.
+func InterpolateHourly(hours []HourlyForecast) calc.Weather {
+	// the first record is at 05:00, records without increasing timestamps
+	// are taken as consecutive hours
+	minutes := interop.HourlyMinutes(len(hours))
+	timed := len(hours) > 1
+	for i := 1; i < len(hours); i++ {
+		timed = timed && hours[i].Dt > hours[i-1].Dt
+	}
+	if timed {
+		for i, h := range hours {
+			minutes[i] = float64(h.Dt-hours[0].Dt) / 60
+		}
+	}
 
-	hourlyTemps := make([]float64, 0)
+	temps := make([]float64, len(hours))
+	for i, h := range hours {
+		temps[i] = h.Temp
+	}
+	return interpolateWeather(hours, minutes, minutes, temps)
+}
 
-	for _, hourly := range forecast.Hourly {
-		// only get the data from 5 AM to 7 PM.
-		hourlyTime := time.Unix(hourly.Dt, 0).In(loc)
-		if hourlyTime.Before(targetDayStart) || hourlyTime.After(targetDayEnd) {
-			continue
+// interpolateWeather resamples the records at minutes, except the temperature
+// which is taken from the checked series at tempMinutes. Wind direction is
+// interpolated through its components so it turns the short way round, and
+// bounded variables are clamped after the spline.
+func interpolateWeather(hours []HourlyForecast, minutes, tempMinutes, temps []float64) calc.Weather {
+	day := func(minutes, values []float64) [840]float64 {
+		d, _ := interop.Default.Day(minutes, values)
+		return d
+	}
+	series := func(get func(h HourlyForecast) float64) [840]float64 {
+		values := make([]float64, len(hours))
+		for i, h := range hours {
+			values[i] = get(h)
+		}
+		return day(minutes, values)
+	}
+	clamp := func(values *[840]float64, lo, hi float64) {
+		for i, v := range values {
+			values[i] = math.Min(math.Max(v, lo), hi)
 		}
+	}
 
-		hourlyTemps = append(hourlyTemps, hourly.Temp)
+	w := calc.Weather{
+		Extended:  true,
+		Temp:      day(tempMinutes, temps),
+		Humidity:  series(func(h HourlyForecast) float64 { return h.Humidity }),
+		DewPoint:  series(func(h HourlyForecast) float64 { return h.DewPoint }),
+		Clouds:    series(func(h HourlyForecast) float64 { return h.Clouds }),
+		WindSpeed: series(func(h HourlyForecast) float64 { return h.WindSpeed }),
+		UVI:       series(func(h HourlyForecast) float64 { return h.UVI }),
+		Pressure:  series(func(h HourlyForecast) float64 { return h.Pressure }),
 	}
+	clamp(&w.Humidity, 0, 100)
+	clamp(&w.Clouds, 0, 100)
+	clamp(&w.WindSpeed, 0, math.Inf(1))
+	clamp(&w.UVI, 0, math.Inf(1))
 
-	// interpolate
-	return interop.MovingWindowInterpolateTemperature(hourlyTemps), nil
-}
\ No newline at end of file
+	u := series(func(h HourlyForecast) float64 { return math.Sin(h.WindDeg * math.Pi / 180) })
+	v := series(func(h HourlyForecast) float64 { return math.Cos(h.WindDeg * math.Pi / 180) })
+	for i := range w.WindDeg {
+		w.WindDeg[i] = math.Mod(math.Atan2(u[i], v[i])*180/math.Pi+360, 360)
+	}
+
+	return w
+}